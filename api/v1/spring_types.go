@@ -16,8 +16,11 @@ limitations under the License.
 package v1
 
 import (
+	apps "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // MicroserviceSpec defines the desired state of Microservice
@@ -28,24 +31,383 @@ type MicroserviceSpec struct {
 	Template corev1.PodTemplateSpec `json:"template,omitempty"`
 	Bindings []string               `json:"bindings,omitempty"`
 	Profiles []string               `json:"profiles,omitempty"`
+	// Secrets for pulling the image. Added to any from the template or bindings.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// Pull policy for the app container. Overrides the template and bindings.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Service account for the pods. Overrides the template and bindings.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Spring Boot properties for the app, rendered into a ConfigMap and mounted in the app container
+	Properties map[string]string `json:"properties,omitempty"`
+	// Contents of an application.yaml (it can have multiple documents) to mount next to the properties
+	ApplicationYAML string `json:"applicationYaml,omitempty"`
+	// Spring Boot properties with values from Secrets or ConfigMaps, passed to the app as env vars
+	PropertiesFrom map[string]PropertyValueSource `json:"propertiesFrom,omitempty"`
+	// Schedule in Cron format. If set the app runs as a CronJob instead of a Deployment.
+	Schedule string `json:"schedule,omitempty"`
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	ConcurrencyPolicy          batchv1.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	SuccessfulJobsHistoryLimit *int32                    `json:"successfulJobsHistoryLimit,omitempty"`
+	FailedJobsHistoryLimit     *int32                    `json:"failedJobsHistoryLimit,omitempty"`
+	// Number of old runs to keep when a job is re-run because its spec changed. Defaults to 3.
+	JobHistoryLimit *int32 `json:"jobHistoryLimit,omitempty"`
+	// The kind of workload that runs the app, Deployment (the default) or StatefulSet
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet
+	Workload WorkloadKind `json:"workload,omitempty"`
+	// Claims for per-pod storage, only used if the workload is a StatefulSet
+	VolumeClaimTemplates []corev1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+	// Number of replicas in the Deployment. Leave it unset if something else (e.g. an HPA) scales the Deployment.
+	Replicas    *int32       `json:"replicas,omitempty"`
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty"`
+	// How to replace old pods with new ones in the Deployment. Defaults to a rolling update.
+	Strategy *apps.DeploymentStrategy `json:"strategy,omitempty"`
+	// Minimum number of seconds a new pod has to be ready before it counts as available
+	// +kubebuilder:validation:Minimum=0
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+	// Maximum number of seconds a rollout can take before it is reported as stalled. Defaults to 600.
+	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+	// Roll the Deployment back to the last healthy pod template if a rollout exceeds its progress deadline
+	AutoRollback bool `json:"autoRollback,omitempty"`
+	// The port the app listens on (server.port). Defaults to 8080.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+	// The port for the actuator endpoints (management.server.port). Defaults to the same as the app.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	ManagementPort int32        `json:"managementPort,omitempty"`
+	Service        *ServiceSpec `json:"service,omitempty"`
+	Ingress        *IngressSpec `json:"ingress,omitempty"`
+	Route          *RouteSpec   `json:"route,omitempty"`
+	// If set, a PodDisruptionBudget is created for the pods
+	DisruptionBudget *DisruptionBudget `json:"disruptionBudget,omitempty"`
+	// If set, a second Deployment runs the canary image behind the same Service
+	Canary *CanarySpec `json:"canary,omitempty"`
+	// If set, changes are rolled out to an inactive colour Deployment, and traffic is switched
+	// when the spring.io/active annotation changes
+	BlueGreen *BlueGreenSpec `json:"blueGreen,omitempty"`
+	// If set, the JVM memory settings are calculated from the memory limit of the app container
+	JVMMemory *JVMMemory `json:"jvmMemory,omitempty"`
+	// If set, the app is shut down gracefully, finishing in-flight requests
+	GracefulShutdown *GracefulShutdown `json:"gracefulShutdown,omitempty"`
+	// If set, liveness, readiness and startup probes are generated from the actuator health groups
+	Probes *Probes `json:"probes,omitempty"`
+	// Run a single replica with the JDWP agent listening on port 5005, and a Service for it
+	Debug bool `json:"debug,omitempty"`
+	// If set, the pods get a ServiceAccount with a Role for the Spring Cloud Kubernetes features
+	SpringCloudKubernetes *SpringCloudKubernetes `json:"springCloudKubernetes,omitempty"`
+	// How the pods pick up changes in the ConfigMaps and Secrets they use: restart (the default)
+	// rolls the pods, refresh and busrefresh call the actuator endpoint with the same name
+	// +kubebuilder:validation:Enum=restart;refresh;busrefresh
+	ConfigReload ConfigReloadMode `json:"configReload,omitempty"`
+	// If set, the tag of the image is looked up in the registry and the pods run the digest it points at
+	ImageResolution *ImageResolution `json:"imageResolution,omitempty"`
+}
+
+// SpringCloudKubernetes selects the Spring Cloud Kubernetes features the app uses, so that it only
+// gets the permissions it needs
+type SpringCloudKubernetes struct {
+	// Read ConfigMaps for configuration
+	Config bool `json:"config,omitempty"`
+	// Read Secrets for configuration
+	Secrets bool `json:"secrets,omitempty"`
+	// Read Services, Endpoints and Pods for discovery
+	Discovery bool `json:"discovery,omitempty"`
+	// Manage Leases for leader election
+	LeaderElection bool `json:"leaderElection,omitempty"`
+}
+
+// ImageResolution configures the lookup of the image digest in the registry. The image pull secrets
+// are used as credentials. Set the spring.io/resolve-image annotation to a new value to look it up again.
+type ImageResolution struct {
+	// How often to look the tag up again (e.g. 10m). If it is not set the digest only changes
+	// when the image or the annotation changes.
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Talk to the registry over plain HTTP, e.g. for a local registry
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// Probes configures the probes generated from the actuator health groups (Spring Boot 2.3 or later)
+type Probes struct {
+	// Base path of the actuator endpoints. Defaults to /actuator.
+	BasePath string `json:"basePath,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+	// Number of failed startup probes before the container is restarted. With the default period
+	// of 10 seconds, the default of 30 gives the app 5 minutes to start.
+	// +kubebuilder:validation:Minimum=1
+	StartupFailureThreshold *int32 `json:"startupFailureThreshold,omitempty"`
+}
+
+// GracefulShutdown configures how the app container is stopped
+type GracefulShutdown struct {
+	// Seconds to wait in a preStop hook, so that the pod can be taken out of the load balancer
+	// before the app starts to shut down. Defaults to 10.
+	// +kubebuilder:validation:Minimum=0
+	PreStopSeconds *int32 `json:"preStopSeconds,omitempty"`
+	// Seconds the app has to finish in-flight requests (spring.lifecycle.timeout-per-shutdown-phase).
+	// Defaults to 30.
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// JVMMemory configures the JVM memory calculator. Heap, metaspace, thread stacks, code cache and
+// direct memory have to fit in the memory limit of the app container.
+type JVMMemory struct {
+	// Number of threads to reserve stack space for. Defaults to 250.
+	// +kubebuilder:validation:Minimum=1
+	ThreadCount *int32 `json:"threadCount,omitempty"`
+	// Estimate of the number of classes the app loads, used to size the metaspace. Defaults to 12000.
+	// +kubebuilder:validation:Minimum=1
+	LoadedClassCount *int32 `json:"loadedClassCount,omitempty"`
+	// Percentage of the memory limit to leave for things outside the JVM. Defaults to 0.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=99
+	HeadRoom *int32 `json:"headRoom,omitempty"`
+}
+
+// BlueGreenSpec describes a blue/green (or red/black) rollout
+type BlueGreenSpec struct {
+	// Switch traffic to the new colour as soon as all its replicas are available
+	AutoPromote bool `json:"autoPromote,omitempty"`
+}
+
+// CanarySpec describes a canary release. The canary gets a share of the traffic in proportion to
+// its replicas, which are either set explicitly or computed from the weight.
+type CanarySpec struct {
+	Image string `json:"image"`
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Percentage of the pods (and therefore the traffic) that should be running the canary
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// PropertyValueSource is a reference to the value of a Spring Boot property. Only one of the
+// fields should be set.
+type PropertyValueSource struct {
+	SecretKeyRef    *corev1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// WorkloadKind is the kind of resource used to run a long-lived app
+type WorkloadKind string
+
+const (
+	// DeploymentWorkload runs the app in a Deployment
+	DeploymentWorkload WorkloadKind = "Deployment"
+	// StatefulSetWorkload runs the app in a StatefulSet with a headless Service
+	StatefulSetWorkload WorkloadKind = "StatefulSet"
+)
+
+// ConfigReloadMode is the way that changes in config reach the running pods
+type ConfigReloadMode string
+
+const (
+	// RestartConfigReload rolls the pods
+	RestartConfigReload ConfigReloadMode = "restart"
+	// RefreshConfigReload POSTs to /actuator/refresh on each ready pod (needs Spring Cloud Context)
+	RefreshConfigReload ConfigReloadMode = "refresh"
+	// BusRefreshConfigReload POSTs to /actuator/busrefresh on one ready pod (needs Spring Cloud Bus)
+	BusRefreshConfigReload ConfigReloadMode = "busrefresh"
+)
+
+// DisruptionBudget configures a PodDisruptionBudget. Only one of minAvailable and maxUnavailable
+// can be used. If neither is set, one pod is allowed to be unavailable.
+type DisruptionBudget struct {
+	MinAvailable   *intstr.IntOrString `json:"minAvailable,omitempty"`
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// ServiceSpec customizes the Service for a Microservice
+type ServiceSpec struct {
+	// Set to true if the app has no endpoints and needs no Service
+	Disabled bool `json:"disabled,omitempty"`
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Set to true for a Service with no cluster IP
+	Headless    bool              `json:"headless,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Additional ports to expose, as well as the app and management ports
+	Ports []corev1.ServicePort `json:"ports,omitempty"`
+}
+
+// Autoscaling configures a HorizontalPodAutoscaler for the Deployment
+type Autoscaling struct {
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas                       int32  `json:"maxReplicas"`
+	TargetCPUUtilizationPercentage    *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+}
+
+// IngressSpec configures an Ingress that routes to the Service for a Microservice
+type IngressSpec struct {
+	Hosts []string `json:"hosts,omitempty"`
+	// Paths to route to the app. Defaults to "/".
+	Paths []string `json:"paths,omitempty"`
+	// The name of the IngressClass that handles the Ingress
+	ClassName string `json:"className,omitempty"`
+	// Name of a Secret with a TLS certificate for the hosts
+	TLSSecretName string            `json:"tlsSecretName,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// RouteSpec configures a Gateway API HTTPRoute that routes to the Service for a Microservice
+type RouteSpec struct {
+	Gateway   GatewayReference `json:"gateway"`
+	Hostnames []string         `json:"hostnames,omitempty"`
+	// Path prefixes to route to the app. Defaults to "/".
+	Paths []string `json:"paths,omitempty"`
+}
+
+// GatewayReference identifies the parent Gateway of an HTTPRoute
+type GatewayReference struct {
+	Name string `json:"name"`
+	// Defaults to the namespace of the Microservice
+	Namespace string `json:"namespace,omitempty"`
+	// The name of a listener in the Gateway
+	SectionName string `json:"sectionName,omitempty"`
 }
 
 // MicroserviceStatus defines the observed state of Microservice
 type MicroserviceStatus struct {
-	ServiceName        string `json:"serviceName,omitempty"`
-	Label              string `json:"label,omitempty"`
-	Running            bool   `json:"running,omitempty"`
-	Complete           bool   `json:"complete,omitempty"`
-	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
+	ServiceName        string            `json:"serviceName,omitempty"`
+	Label              string            `json:"label,omitempty"`
+	Running            bool              `json:"running,omitempty"`
+	Complete           bool              `json:"complete,omitempty"`
+	ObservedGeneration int64             `json:"observedGeneration,omitempty"`
+	LastScheduleTime   *metav1.Time      `json:"lastScheduleTime,omitempty"`
+	LastSuccessfulTime *metav1.Time      `json:"lastSuccessfulTime,omitempty"`
+	Runs               []MicroserviceRun `json:"runs,omitempty"`
+	URL                string            `json:"url,omitempty"`
+	Replicas           int32             `json:"replicas,omitempty"`
+	UpdatedReplicas    int32             `json:"updatedReplicas,omitempty"`
+	ReadyReplicas      int32             `json:"readyReplicas,omitempty"`
+	AvailableReplicas  int32             `json:"availableReplicas,omitempty"`
+	Canary             *CanaryStatus     `json:"canary,omitempty"`
+	BlueGreen          *BlueGreenStatus  `json:"blueGreen,omitempty"`
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Hash of the last pod template that was rolled out successfully
+	HealthyRevision string `json:"healthyRevision,omitempty"`
+	// Hash of the last pod template that failed to roll out and was rolled back
+	FailedRevision string `json:"failedRevision,omitempty"`
+	// References in propertiesFrom that can't be resolved
+	MissingProperties []string `json:"missingProperties,omitempty"`
+	// Hash of the data in the ConfigMaps and Secrets that the pod template refers to
+	ConfigHash   string              `json:"configHash,omitempty"`
+	ConfigReload *ConfigReloadStatus `json:"configReload,omitempty"`
+	Image        *ImageStatus        `json:"image,omitempty"`
+}
+
+// BlueGreenStatus defines the observed state of a blue/green rollout
+type BlueGreenStatus struct {
+	ActiveColor  string `json:"activeColor,omitempty"`
+	PreviewColor string `json:"previewColor,omitempty"`
+	// True if the preview has the latest pod template and all its replicas are available
+	PreviewReady bool `json:"previewReady,omitempty"`
+	// True once the main Service selects the active colour (it waits until the colour is available
+	// the first time, so switching to blue/green does not interrupt the traffic)
+	Switched bool `json:"switched,omitempty"`
+}
+
+// CanaryStatus defines the observed state of the canary Deployment
+type CanaryStatus struct {
+	DeploymentName string `json:"deploymentName,omitempty"`
+	Image          string `json:"image,omitempty"`
+	Replicas       int32  `json:"replicas,omitempty"`
+	ReadyReplicas  int32  `json:"readyReplicas,omitempty"`
+	// Promoted or Aborted if the canary image was promoted or aborted with the annotation (the outcome
+	// itself is kept in the spring.io/canary-promoted or spring.io/canary-aborted annotation)
+	Outcome string `json:"outcome,omitempty"`
 }
 
+// Outcomes of a canary that was promoted or aborted with the annotation
+const (
+	CanaryPromoted = "Promoted"
+	CanaryAborted  = "Aborted"
+)
+
+// ConfigReloadStatus records how config changes were applied to the pods without restarting them
+type ConfigReloadStatus struct {
+	// The config hash on the pod template, which only changes if the pods have to restart
+	TemplateHash string `json:"templateHash,omitempty"`
+	// The config hash that the pods last refreshed (or restarted) with
+	ConfigHash string `json:"configHash,omitempty"`
+	// Hash of the config that reaches the pods as env vars, which can only change with a restart
+	EnvHash string `json:"envHash,omitempty"`
+	// When the current refresh started, if it is still waiting for the pods to see the change
+	PendingSince    *metav1.Time       `json:"pendingSince,omitempty"`
+	LastRefreshTime *metav1.Time       `json:"lastRefreshTime,omitempty"`
+	Pods            []PodRefreshStatus `json:"pods,omitempty"`
+}
+
+// ImageStatus records the digest that the image was resolved to
+type ImageStatus struct {
+	// The image from the spec
+	Image string `json:"image,omitempty"`
+	// The digest that the tag pointed at, e.g. sha256:...
+	Digest           string       `json:"digest,omitempty"`
+	LastResolvedTime *metav1.Time `json:"lastResolvedTime,omitempty"`
+	// The value of the spring.io/resolve-image annotation when it was resolved
+	Trigger string `json:"trigger,omitempty"`
+}
+
+// PodRefreshStatus is the outcome of the last refresh of one pod
+type PodRefreshStatus struct {
+	Name      string `json:"name"`
+	Succeeded bool   `json:"succeeded"`
+	// The properties that changed, as reported by the actuator, or the error
+	Message string `json:"message,omitempty"`
+}
+
+// MicroserviceRun records the outcome of one of the Jobs created for a Microservice
+type MicroserviceRun struct {
+	Name string `json:"name"`
+	// Outcome is one of Running, Succeeded or Failed
+	Outcome        string       `json:"outcome,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// The types of the conditions in the MicroserviceStatus
+const (
+	// MicroserviceSucceeded is true when a job has completed and false when it has failed
+	MicroserviceSucceeded = "Succeeded"
+	// MicroserviceRouteAccepted reflects whether the parent Gateway accepted the HTTPRoute
+	MicroserviceRouteAccepted = "RouteAccepted"
+	// MicroserviceProgressing is false when a rollout has exceeded its progress deadline
+	MicroserviceProgressing = "Progressing"
+	// MicroserviceRolledBack is true when the Deployment is running the last healthy pod template
+	// instead of the latest one
+	MicroserviceRolledBack = "RolledBack"
+	// MicroserviceMemoryCalculated is false if the JVM memory settings do not fit in the memory limit
+	MicroserviceMemoryCalculated = "MemoryCalculated"
+	// MicroservicePropertiesResolved is false if any of the propertiesFrom refer to a missing key
+	MicroservicePropertiesResolved = "PropertiesResolved"
+	// MicroservicePodReferencesResolved is false if an image pull secret or the service account is missing
+	MicroservicePodReferencesResolved = "PodReferencesResolved"
+	// MicroserviceImageResolved is false if the image digest can't be looked up in the registry
+	MicroserviceImageResolved = "ImageResolved"
+	// MicroserviceDisruptionBudgetEffective is false if the PodDisruptionBudget only has one replica to protect
+	MicroserviceDisruptionBudgetEffective = "DisruptionBudgetEffective"
+)
+
 // +kubebuilder:object:root=true
 
 // Microservice is the Schema for the springs API
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Image",type="string",JSONPath=".spec.image",description="image label"
 // +kubebuilder:printcolumn:name="Running",type="boolean",JSONPath=".status.running",description="deployment status"
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas",description="ready replicas"
 // +kubebuilder:printcolumn:name="Complete",type="boolean",JSONPath=".status.complete",description="completion status"
+// +kubebuilder:printcolumn:name="URL",type="string",JSONPath=".status.url",description="ingress URL"
 type Microservice struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
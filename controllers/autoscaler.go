@@ -0,0 +1,107 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	autoscaling "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+// HorizontalPodAutoscalerReconciler creates a new HorizontalPodAutoscaler if needed
+func HorizontalPodAutoscalerReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("HorizontalPodAutoscaler")
+
+	return &reconcilers.ChildReconciler{
+		Config:        c,
+		ChildType:     &autoscaling.HorizontalPodAutoscaler{},
+		ChildListType: &autoscaling.HorizontalPodAutoscalerList{},
+
+		DesiredChild: func(ctx context.Context, micro *api.Microservice) (*autoscaling.HorizontalPodAutoscaler, error) {
+			return createAutoscaler(micro), nil
+		},
+
+		ReflectChildStatusOnParent: func(micro *api.Microservice, child *autoscaling.HorizontalPodAutoscaler, err error) {
+			return
+		},
+
+		MergeBeforeUpdate: func(current, desired *autoscaling.HorizontalPodAutoscaler) {
+			current.Labels = desired.Labels
+			current.Spec = desired.Spec
+		},
+
+		SemanticEquals: func(a1, a2 *autoscaling.HorizontalPodAutoscaler) bool {
+			return equality.Semantic.DeepEqual(a1.Spec, a2.Spec) &&
+				equality.Semantic.DeepEqual(a1.Labels, a2.Labels)
+		},
+
+		Sanitize: func(child *autoscaling.HorizontalPodAutoscaler) interface{} {
+			return child.Spec
+		},
+	}
+}
+
+func createAutoscaler(micro *api.Microservice) *autoscaling.HorizontalPodAutoscaler {
+	if micro.Spec.Autoscaling == nil || runsToCompletion(micro) || isBlueGreen(micro) || micro.Spec.Debug {
+		return nil
+	}
+	spec := micro.Spec.Autoscaling
+	autoscaler := &autoscaling.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    map[string]string{"app": micro.Name},
+			Name:      micro.Name,
+			Namespace: micro.Namespace,
+		},
+		Spec: autoscaling.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscaling.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       micro.Name,
+			},
+			MinReplicas: spec.MinReplicas,
+			MaxReplicas: spec.MaxReplicas,
+		},
+	}
+	if spec.TargetCPUUtilizationPercentage != nil {
+		autoscaler.Spec.Metrics = append(autoscaler.Spec.Metrics, resourceMetric(corev1.ResourceCPU, spec.TargetCPUUtilizationPercentage))
+	}
+	if spec.TargetMemoryUtilizationPercentage != nil {
+		autoscaler.Spec.Metrics = append(autoscaler.Spec.Metrics, resourceMetric(corev1.ResourceMemory, spec.TargetMemoryUtilizationPercentage))
+	}
+	if isStatefulSet(micro) {
+		autoscaler.Spec.ScaleTargetRef.Kind = "StatefulSet"
+	}
+	return autoscaler
+}
+
+func resourceMetric(name corev1.ResourceName, utilization *int32) autoscaling.MetricSpec {
+	return autoscaling.MetricSpec{
+		Type: autoscaling.ResourceMetricSourceType,
+		Resource: &autoscaling.ResourceMetricSource{
+			Name: name,
+			Target: autoscaling.MetricTarget{
+				Type:               autoscaling.UtilizationMetricType,
+				AverageUtilization: utilization,
+			},
+		},
+	}
+}
@@ -0,0 +1,87 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateAutoscaler(t *testing.T) {
+	min := int32(2)
+	cpu := int32(70)
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "springguides/demo",
+			Autoscaling: &api.Autoscaling{
+				MinReplicas:                    &min,
+				MaxReplicas:                    5,
+				TargetCPUUtilizationPercentage: &cpu,
+			},
+		},
+	}
+	autoscaler := createAutoscaler(&micro)
+	if autoscaler.Name != "demo" {
+		t.Errorf("HorizontalPodAutoscaler.Name = %s; want 'demo'", autoscaler.Name)
+	}
+	if autoscaler.Spec.ScaleTargetRef.Kind != "Deployment" || autoscaler.Spec.ScaleTargetRef.Name != "demo" {
+		t.Errorf("ScaleTargetRef = %v; want Deployment 'demo'", autoscaler.Spec.ScaleTargetRef)
+	}
+	if *autoscaler.Spec.MinReplicas != 2 {
+		t.Errorf("MinReplicas = %d; want 2", *autoscaler.Spec.MinReplicas)
+	}
+	if autoscaler.Spec.MaxReplicas != 5 {
+		t.Errorf("MaxReplicas = %d; want 5", autoscaler.Spec.MaxReplicas)
+	}
+	if len(autoscaler.Spec.Metrics) != 1 {
+		t.Fatalf("len(Metrics) = %d; want 1", len(autoscaler.Spec.Metrics))
+	}
+	metric := autoscaler.Spec.Metrics[0].Resource
+	if metric.Name != corev1.ResourceCPU || *metric.Target.AverageUtilization != 70 {
+		t.Errorf("Metrics[0] = %s %d; want cpu 70", metric.Name, *metric.Target.AverageUtilization)
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	if deployment.Spec.Replicas != nil {
+		t.Errorf("Deployment.Spec.Replicas = %d; want nil", *deployment.Spec.Replicas)
+	}
+}
+
+func TestCreateAutoscalerNotNeeded(t *testing.T) {
+	replicas := int32(3)
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "demo",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:    "springguides/demo",
+			Replicas: &replicas,
+		},
+	}
+	if autoscaler := createAutoscaler(&micro); autoscaler != nil {
+		t.Errorf("HorizontalPodAutoscaler = %v; want nil", autoscaler)
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 3 {
+		t.Errorf("Deployment.Spec.Replicas = %v; want 3", deployment.Spec.Replicas)
+	}
+}
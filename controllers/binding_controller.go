@@ -17,6 +17,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/vmware-labs/reconciler-runtime/reconcilers"
@@ -35,7 +36,7 @@ func ServiceBindingReconciler(c reconcilers.Config) *reconcilers.ParentReconcile
 
 	return &reconcilers.ParentReconciler{
 		Type: &api.ServiceBinding{},
-		SubReconcilers: []reconcilers.SubReconciler{
+		Reconciler: reconcilers.Sequence{
 			BindingDeploymentReconciler(c),
 		},
 
@@ -77,12 +78,12 @@ func BindingDeploymentReconciler(c reconcilers.Config) reconcilers.SubReconciler
 					micro.Spec.Template.Spec.Containers = []v1.Container{}
 				}
 				annos := micro.ObjectMeta.GetAnnotations()
-				// Add an annotation to jog the API server to update the deployment if necessary
-				if annos["spring.io/active"] == "red" {
-					annos["spring.io/active"] = "black"
-				} else {
-					annos["spring.io/active"] = "red"
+				if annos == nil {
+					annos = map[string]string{}
 				}
+				// Add an annotation to jog the API server to update the deployment if necessary
+				annos["spring.io/binding"] = fmt.Sprintf("%s/%s@%s", binding.Namespace, binding.Name, binding.ResourceVersion)
+				micro.ObjectMeta.SetAnnotations(annos)
 				if err := c.Update(ctx, &micro); err != nil {
 					if apierrors.IsConflict(err) {
 						c.Log.Info("Unable to update Microservice: reason conflict. Will retry on next event.")
@@ -99,8 +100,8 @@ func BindingDeploymentReconciler(c reconcilers.Config) reconcilers.SubReconciler
 
 		Config: c,
 
-		Setup: func(mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
-			bldr.Watches(&source.Kind{Type: &api.Microservice{}}, reconcilers.EnqueueTracked(&api.Microservice{}, c.Tracker, c.Scheme))
+		Setup: func(ctx context.Context, mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
+			bldr.Watches(&source.Kind{Type: &api.Microservice{}}, reconcilers.EnqueueTracked(&api.Microservice{}, c.Tracker, c.Scheme()))
 			return nil
 		},
 	}
@@ -0,0 +1,332 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var activeAnnotation = "spring.io/active"
+var colorLabel = "spring.io/color"
+var specHashAnnotation = "spring.io/spec-hash"
+var colors = []string{"red", "black"}
+
+// BlueGreenReconciler rolls changes out to the inactive colour Deployment, with a preview Service
+// in front of it, and promotes it by switching the active colour. Switching blue/green on or off
+// keeps the old Deployment serving traffic until its replacement is available.
+func BlueGreenReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("BlueGreen")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) error {
+			current := map[string]*apps.Deployment{}
+			for _, color := range colors {
+				key := client.ObjectKey{Namespace: micro.Namespace, Name: colorName(micro, color)}
+				deployment, err := getOwned(ctx, c, key, &apps.Deployment{})
+				if err != nil {
+					return err
+				}
+				if deployment != nil {
+					current[color] = deployment.(*apps.Deployment)
+				}
+			}
+			key := client.ObjectKey{Namespace: micro.Namespace, Name: previewName(micro)}
+			service, err := getOwned(ctx, c, key, &corev1.Service{})
+			if err != nil {
+				return err
+			}
+			if !isBlueGreen(micro) {
+				micro.Status.BlueGreen = nil
+				replaced, err := replacementAvailable(ctx, c, micro)
+				if err != nil {
+					return err
+				}
+				for color, deployment := range current {
+					if color == activeColor(micro) && !replaced {
+						continue
+					}
+					if err := ownedColor.delete(ctx, c, micro, deployment); err != nil {
+						return err
+					}
+				}
+				return ownedService.delete(ctx, c, micro, service)
+			}
+
+			bindings := resolveBindings(c, micro)
+			active := activeColor(micro)
+			preview := otherColor(active)
+
+			desired := createColorDeployment(bindings, micro, active)
+			if current[active] != nil {
+				// The active colour only gets a new pod template when it is promoted
+				desired.Spec.Template = current[active].Spec.Template
+				desired.Annotations[specHashAnnotation] = current[active].Annotations[specHashAnnotation]
+			}
+			activeDeployment, err := applyColor(ctx, c, micro, current[active], desired)
+			if err != nil || activeDeployment == nil {
+				return err
+			}
+
+			desired = createColorDeployment(bindings, micro, preview)
+			hash := desired.Annotations[specHashAnnotation]
+			previewDeployment := current[preview]
+			if activeDeployment.Annotations[specHashAnnotation] == hash {
+				// Nothing to preview, so the old colour is kept around, but scaled down
+				if previewDeployment != nil {
+					desired = previewDeployment.DeepCopy()
+					zero := int32(0)
+					desired.Spec.Replicas = &zero
+				} else {
+					desired = nil
+				}
+			}
+			if desired != nil {
+				if previewDeployment, err = applyColor(ctx, c, micro, previewDeployment, desired); err != nil {
+					return err
+				}
+			}
+			ready := previewDeployment != nil && previewDeployment.Annotations[specHashAnnotation] == hash &&
+				activeDeployment.Annotations[specHashAnnotation] != hash && isAvailable(previewDeployment)
+
+			if ready && micro.Spec.BlueGreen.AutoPromote {
+				if err := promoteColor(ctx, c, micro, preview); err != nil {
+					return err
+				}
+				active, preview = preview, active
+				activeDeployment, previewDeployment = previewDeployment, activeDeployment
+				ready = false
+			}
+
+			if err := applyOwnedService(ctx, c, micro, service, createPreviewService(micro, preview)); err != nil {
+				return err
+			}
+			reflectDeploymentStatus(micro, activeDeployment)
+			micro.Status.BlueGreen = &api.BlueGreenStatus{
+				ActiveColor:  active,
+				PreviewColor: preview,
+				PreviewReady: ready,
+				Switched:     blueGreenSwitched(micro) || isAvailable(activeDeployment),
+			}
+			return nil
+		},
+
+		Config: c,
+
+		Setup: func(ctx context.Context, mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
+			bldr.Watches(&source.Kind{Type: &apps.Deployment{}}, &handler.EnqueueRequestForOwner{
+				OwnerType:    &api.Microservice{},
+				IsController: false,
+			})
+			bldr.Watches(&source.Kind{Type: &corev1.Service{}}, &handler.EnqueueRequestForOwner{
+				OwnerType:    &api.Microservice{},
+				IsController: false,
+			})
+			return nil
+		},
+	}
+}
+
+func isBlueGreen(micro *api.Microservice) bool {
+	return micro.Spec.BlueGreen != nil && !runsToCompletion(micro) && !isStatefulSet(micro)
+}
+
+// True once the main Service has been switched over to the colour Deployments (the first time the
+// active colour was available)
+func blueGreenSwitched(micro *api.Microservice) bool {
+	return isBlueGreen(micro) && micro.Status.BlueGreen != nil && micro.Status.BlueGreen.Switched
+}
+
+// True if blue/green is off and the main Deployment that takes over from the colours is available
+// (or there is no Deployment to wait for)
+func replacementAvailable(ctx context.Context, c reconcilers.Config, micro *api.Microservice) (bool, error) {
+	if runsToCompletion(micro) || isStatefulSet(micro) {
+		return true, nil
+	}
+	var deployment apps.Deployment
+	if err := c.Get(ctx, client.ObjectKey{Namespace: micro.Namespace, Name: micro.Name}, &deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return isAvailable(&deployment), nil
+}
+
+// The colour that gets the traffic from the main Service, from the annotation if there is one
+func activeColor(micro *api.Microservice) string {
+	if color := micro.Annotations[activeAnnotation]; color == colors[1] {
+		return color
+	}
+	return colors[0]
+}
+
+func otherColor(color string) string {
+	if color == colors[0] {
+		return colors[1]
+	}
+	return colors[0]
+}
+
+func colorName(micro *api.Microservice, color string) string {
+	return fmt.Sprintf("%s-%s", micro.Name, color)
+}
+
+func previewName(micro *api.Microservice) string {
+	return fmt.Sprintf("%s-preview", micro.Name)
+}
+
+func createColorDeployment(bindings []api.ServiceBinding, micro *api.Microservice, color string) *apps.Deployment {
+	labels := map[string]string{"app": micro.Name, colorLabel: color}
+	replicas := int32(1)
+	if micro.Spec.Replicas != nil && !micro.Spec.Debug {
+		replicas = *micro.Spec.Replicas
+	}
+	deployment := &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    labels,
+			Name:      colorName(micro, color),
+			Namespace: micro.Namespace,
+		},
+		Spec: apps.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			MinReadySeconds:         micro.Spec.MinReadySeconds,
+			ProgressDeadlineSeconds: micro.Spec.ProgressDeadlineSeconds,
+			Template:                corev1.PodTemplateSpec{},
+		},
+	}
+	deployment.Spec.Template = *updatePodTemplate(&deployment.Spec.Template, bindings, micro)
+	// Hash before the colour label goes on, so that both colours agree on the hash
+	deployment.Annotations = map[string]string{specHashAnnotation: computeHash(deployment.Spec.Template)}
+	deployment.Spec.Template.Labels[colorLabel] = color
+	return deployment
+}
+
+// A Service like the main one, but pointing at the preview colour
+func createPreviewService(micro *api.Microservice, color string) *corev1.Service {
+	main := createService(micro)
+	if main == nil {
+		return nil
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    main.Labels,
+			Name:      previewName(micro),
+			Namespace: micro.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: main.Spec.Selector,
+		},
+	}
+	service.Spec.Selector[colorLabel] = color
+	for _, port := range main.Spec.Ports {
+		port.NodePort = 0
+		service.Spec.Ports = append(service.Spec.Ports, port)
+	}
+	service.Annotations = map[string]string{specHashAnnotation: computeHash(service.Spec)}
+	return service
+}
+
+// True if the latest rollout of the Deployment is complete
+func isAvailable(deployment *apps.Deployment) bool {
+	return deployment.Status.ObservedGeneration >= deployment.Generation && rolledOut(deployment)
+}
+
+var ownedColor = ownedObject{
+	Kind: "Deployment",
+	SemanticEquals: func(current, desired client.Object) bool {
+		c, d := current.(*apps.Deployment), desired.(*apps.Deployment)
+		return c.Annotations[specHashAnnotation] == d.Annotations[specHashAnnotation] &&
+			*c.Spec.Replicas == *d.Spec.Replicas
+	},
+	MergeBeforeUpdate: func(current, desired client.Object) {
+		c, d := current.(*apps.Deployment), desired.(*apps.Deployment)
+		c.Labels = d.Labels
+		c.Annotations = reconcilers.MergeMaps(c.Annotations, d.Annotations)
+		c.Spec.Replicas = d.Spec.Replicas
+		c.Spec.MinReadySeconds = d.Spec.MinReadySeconds
+		c.Spec.ProgressDeadlineSeconds = d.Spec.ProgressDeadlineSeconds
+		c.Spec.Template = d.Spec.Template
+	},
+}
+
+// Create or update a colour Deployment, returning nil if there is one already that is not ours
+func applyColor(ctx context.Context, c reconcilers.Config, micro *api.Microservice, current, desired *apps.Deployment) (*apps.Deployment, error) {
+	var existing client.Object
+	if current != nil {
+		existing = current
+	}
+	deployment, err := ownedColor.apply(ctx, c, micro, existing, desired)
+	if err != nil || deployment == nil {
+		return nil, err
+	}
+	return deployment.(*apps.Deployment), nil
+}
+
+// The preview and debug Services
+var ownedService = ownedObject{
+	Kind: "Service",
+	SemanticEquals: func(current, desired client.Object) bool {
+		return current.GetAnnotations()[specHashAnnotation] == desired.GetAnnotations()[specHashAnnotation]
+	},
+	MergeBeforeUpdate: func(current, desired client.Object) {
+		c, d := current.(*corev1.Service), desired.(*corev1.Service)
+		c.Labels = d.Labels
+		c.Annotations = reconcilers.MergeMaps(c.Annotations, d.Annotations)
+		c.Spec.Selector = d.Spec.Selector
+		c.Spec.Ports = d.Spec.Ports
+	},
+}
+
+// Create, update or delete a Service (current is nil if there is none, and so is desired if there
+// should be none)
+func applyOwnedService(ctx context.Context, c reconcilers.Config, micro *api.Microservice, current client.Object, desired *corev1.Service) error {
+	if desired == nil {
+		return ownedService.delete(ctx, c, micro, current)
+	}
+	_, err := ownedService.apply(ctx, c, micro, current, desired)
+	return err
+}
+
+// Switch the traffic to a new colour by updating the annotation on the Microservice
+func promoteColor(ctx context.Context, c reconcilers.Config, micro *api.Microservice, color string) error {
+	if micro.Annotations == nil {
+		micro.Annotations = map[string]string{}
+	}
+	micro.Annotations[activeAnnotation] = color
+	status := micro.Status
+	if err := c.Update(ctx, micro); err != nil {
+		return err
+	}
+	micro.Status = status
+	c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Promoted", "Switched traffic to %q", colorName(micro, color))
+	return nil
+}
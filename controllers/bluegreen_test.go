@@ -0,0 +1,118 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateColorDeployment(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{BlueGreen: &api.BlueGreenSpec{}})
+	red := createColorDeployment([]api.ServiceBinding{}, &micro, "red")
+	black := createColorDeployment([]api.ServiceBinding{}, &micro, "black")
+	if red.Name != "demo-red" {
+		t.Errorf("Deployment.Name = %s; want 'demo-red'", red.Name)
+	}
+	if red.Spec.Selector.MatchLabels["spring.io/color"] != "red" {
+		t.Errorf("Deployment.Spec.Selector = %s; want 'spring.io/color=red'", red.Spec.Selector.MatchLabels)
+	}
+	if black.Spec.Template.Labels["spring.io/color"] != "black" {
+		t.Errorf("Template.Labels = %s; want 'spring.io/color=black'", black.Spec.Template.Labels)
+	}
+	if red.Annotations["spring.io/spec-hash"] != black.Annotations["spring.io/spec-hash"] {
+		t.Errorf("Hash = %s; want %s", black.Annotations["spring.io/spec-hash"], red.Annotations["spring.io/spec-hash"])
+	}
+	micro.Spec.Image = "springguides/demo:v2"
+	changed := createColorDeployment([]api.ServiceBinding{}, &micro, "red")
+	if red.Annotations["spring.io/spec-hash"] == changed.Annotations["spring.io/spec-hash"] {
+		t.Errorf("Hash = %s; want it to change", changed.Annotations["spring.io/spec-hash"])
+	}
+}
+
+func TestBlueGreenServices(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{BlueGreen: &api.BlueGreenSpec{}})
+	service := createService(&micro)
+	if _, ok := service.Spec.Selector["spring.io/color"]; ok {
+		t.Errorf("Service.Spec.Selector = %s; want no colour before the switch", service.Spec.Selector)
+	}
+	micro.Status.BlueGreen = &api.BlueGreenStatus{Switched: true}
+	service = createService(&micro)
+	if service.Spec.Selector["spring.io/color"] != "red" {
+		t.Errorf("Service.Spec.Selector = %s; want 'spring.io/color=red'", service.Spec.Selector)
+	}
+	micro.Annotations = map[string]string{"spring.io/active": "black"}
+	service = createService(&micro)
+	if service.Spec.Selector["spring.io/color"] != "black" {
+		t.Errorf("Service.Spec.Selector = %s; want 'spring.io/color=black'", service.Spec.Selector)
+	}
+	preview := createPreviewService(&micro, otherColor(activeColor(&micro)))
+	if preview.Name != "demo-preview" {
+		t.Errorf("Service.Name = %s; want 'demo-preview'", preview.Name)
+	}
+	if preview.Spec.Selector["spring.io/color"] != "red" || preview.Spec.Selector["app"] != "demo" {
+		t.Errorf("Service.Spec.Selector = %s; want 'app=demo,spring.io/color=red'", preview.Spec.Selector)
+	}
+	if len(preview.Spec.Ports) != 1 || preview.Spec.Ports[0].Name != "http" {
+		t.Errorf("Service.Spec.Ports = %v; want 'http'", preview.Spec.Ports)
+	}
+	micro.Spec.BlueGreen = nil
+	service = createService(&micro)
+	if _, ok := service.Spec.Selector["spring.io/color"]; ok {
+		t.Errorf("Service.Spec.Selector = %s; want no colour when blue/green is off", service.Spec.Selector)
+	}
+}
+
+func TestActiveColor(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{BlueGreen: &api.BlueGreenSpec{}})
+	if activeColor(&micro) != "red" {
+		t.Errorf("activeColor() = %s; want 'red'", activeColor(&micro))
+	}
+	micro.Annotations = map[string]string{"spring.io/active": "green"}
+	if activeColor(&micro) != "red" {
+		t.Errorf("activeColor() = %s; want 'red'", activeColor(&micro))
+	}
+	if !isBlueGreen(&micro) {
+		t.Errorf("isBlueGreen() = false; want true")
+	}
+	if createCanary([]api.ServiceBinding{}, &micro) != nil || createAutoscaler(&micro) != nil {
+		t.Errorf("Canary or autoscaler = not nil; want nil")
+	}
+}
+
+func TestIsAvailable(t *testing.T) {
+	replicas := int32(2)
+	deployment := apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+		Spec:       apps.DeploymentSpec{Replicas: &replicas},
+		Status: apps.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+		},
+	}
+	if isAvailable(&deployment) {
+		t.Errorf("isAvailable() = true; want false")
+	}
+	deployment.Status.ObservedGeneration = 3
+	if !isAvailable(&deployment) {
+		t.Errorf("isAvailable() = false; want true")
+	}
+}
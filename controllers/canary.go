@@ -0,0 +1,223 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var canaryAnnotation = "spring.io/canary"
+var canaryPromotedAnnotation = "spring.io/canary-promoted"
+var canaryAbortedAnnotation = "spring.io/canary-aborted"
+var trackLabel = "spring.io/track"
+
+var ownedCanary = ownedObject{
+	Kind: "Deployment",
+	SemanticEquals: func(current, desired client.Object) bool {
+		c, d := current.(*apps.Deployment), desired.(*apps.Deployment)
+		return equality.Semantic.DeepEqual(c.Spec.Replicas, d.Spec.Replicas) &&
+			equality.Semantic.DeepEqual(c.Spec.Template, d.Spec.Template) &&
+			equality.Semantic.DeepEqual(c.Labels, d.Labels)
+	},
+	MergeBeforeUpdate: func(current, desired client.Object) {
+		c, d := current.(*apps.Deployment), desired.(*apps.Deployment)
+		c.Labels = d.Labels
+		c.Spec.Replicas = d.Spec.Replicas
+		c.Spec.Template = d.Spec.Template
+	},
+}
+
+// CanaryReconciler runs the canary Deployment next to the main one, and promotes or aborts it
+// if asked to by an annotation
+func CanaryReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("Canary")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) error {
+			if err := applyCanaryAnnotation(ctx, c, micro); err != nil {
+				return err
+			}
+			key := client.ObjectKey{Namespace: micro.Namespace, Name: canaryName(micro)}
+			current, err := getOwned(ctx, c, key, &apps.Deployment{})
+			if err != nil {
+				return err
+			}
+			desired := createCanary(resolveBindings(c, micro), micro)
+			if desired == nil {
+				micro.Status.Canary = nil
+				if outcome := canaryOutcome(micro); outcome != "" {
+					micro.Status.Canary = &api.CanaryStatus{Image: micro.Spec.Canary.Image, Outcome: outcome}
+				}
+				return ownedCanary.delete(ctx, c, micro, current)
+			}
+			canary, err := ownedCanary.apply(ctx, c, micro, current, desired)
+			if err != nil {
+				return err
+			}
+			if canary == nil {
+				micro.Status.Canary = nil
+				return nil
+			}
+			reflectCanaryStatus(micro, canary.(*apps.Deployment))
+			return nil
+		},
+
+		Config: c,
+
+		Setup: func(ctx context.Context, mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
+			bldr.Watches(&source.Kind{Type: &apps.Deployment{}}, &handler.EnqueueRequestForOwner{
+				OwnerType:    &api.Microservice{},
+				IsController: false,
+			})
+			return nil
+		},
+	}
+}
+
+// Record a promote or abort from the annotation against the current canary image, in another
+// annotation so that it survives the status being rewritten, and remove the request. The spec is left
+// alone: a promoted canary image is used for the main Deployment until the spec catches up (or the
+// canary changes).
+func applyCanaryAnnotation(ctx context.Context, c reconcilers.Config, micro *api.Microservice) error {
+	action, ok := micro.Annotations[canaryAnnotation]
+	if !ok {
+		return nil
+	}
+	record := ""
+	switch action {
+	case "promote":
+		record = canaryPromotedAnnotation
+	case "abort":
+		record = canaryAbortedAnnotation
+	default:
+		c.Recorder.Eventf(micro, corev1.EventTypeWarning, "InvalidAnnotation",
+			"Annotation %s=%q should be 'promote' or 'abort'", canaryAnnotation, action)
+		return nil
+	}
+	patch := client.MergeFrom(micro.DeepCopy())
+	delete(micro.Annotations, canaryAnnotation)
+	if micro.Spec.Canary != nil {
+		delete(micro.Annotations, canaryPromotedAnnotation)
+		delete(micro.Annotations, canaryAbortedAnnotation)
+		micro.Annotations[record] = micro.Spec.Canary.Image
+	}
+	// The patch response has the stored status, not the one being reconciled
+	status := micro.Status
+	if err := c.Patch(ctx, micro, patch); err != nil {
+		return err
+	}
+	micro.Status = status
+	if micro.Spec.Canary == nil {
+		return nil
+	}
+	switch record {
+	case canaryPromotedAnnotation:
+		c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Promoted", "Promoted canary image %q", micro.Spec.Canary.Image)
+	case canaryAbortedAnnotation:
+		c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Aborted", "Aborted canary image %q", micro.Spec.Canary.Image)
+	}
+	return nil
+}
+
+// The outcome recorded in the annotations for the canary image in the spec, or "" if it is still
+// running
+func canaryOutcome(micro *api.Microservice) string {
+	if micro.Spec.Canary == nil || micro.Spec.Canary.Image == "" {
+		return ""
+	}
+	switch micro.Spec.Canary.Image {
+	case micro.Annotations[canaryPromotedAnnotation]:
+		return api.CanaryPromoted
+	case micro.Annotations[canaryAbortedAnnotation]:
+		return api.CanaryAborted
+	}
+	return ""
+}
+
+func createCanary(bindings []api.ServiceBinding, micro *api.Microservice) *apps.Deployment {
+	// No canary while debugging, so the debug Service always ends up in the stable pod
+	if micro.Spec.Canary == nil || runsToCompletion(micro) || isStatefulSet(micro) || isBlueGreen(micro) ||
+		micro.Spec.Debug || canaryOutcome(micro) != "" {
+		return nil
+	}
+	labels := map[string]string{"app": micro.Name, trackLabel: "canary"}
+	replicas := canaryReplicas(micro)
+	deployment := &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    labels,
+			Name:      canaryName(micro),
+			Namespace: micro.Namespace,
+		},
+		Spec: apps.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{},
+		},
+	}
+	canary := micro.DeepCopy()
+	canary.Spec.Image = micro.Spec.Canary.Image
+	deployment.Spec.Template = *updatePodTemplate(&deployment.Spec.Template, bindings, canary)
+	// The Service selects on the app label, so the canary gets a share of the traffic
+	deployment.Spec.Template.Labels[trackLabel] = "canary"
+	return deployment
+}
+
+// The number of canary replicas. If it is not explicit, it is chosen so that the canary is (roughly)
+// the desired percentage of all the replicas.
+func canaryReplicas(micro *api.Microservice) int32 {
+	canary := micro.Spec.Canary
+	if canary.Replicas != nil {
+		return *canary.Replicas
+	}
+	if canary.Weight == nil {
+		return 1
+	}
+	weight := *canary.Weight
+	stable := minimumReplicas(micro)
+	replicas := (stable*weight + (100 - weight) - 1) / (100 - weight)
+	if replicas < 1 {
+		replicas = 1
+	}
+	return replicas
+}
+
+func canaryName(micro *api.Microservice) string {
+	return fmt.Sprintf("%s-canary", micro.Name)
+}
+
+func reflectCanaryStatus(micro *api.Microservice, deployment *apps.Deployment) {
+	micro.Status.Canary = &api.CanaryStatus{
+		DeploymentName: deployment.Name,
+		Image:          findAppContainer(&deployment.Spec.Template.Spec).Image,
+		Replicas:       deployment.Status.Replicas,
+		ReadyReplicas:  deployment.Status.ReadyReplicas,
+	}
+}
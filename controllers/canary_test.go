@@ -0,0 +1,121 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateCanary(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:  "springguides/demo",
+			Canary: &api.CanarySpec{Image: "springguides/demo:v2"},
+		},
+	}
+	deployment := createCanary([]api.ServiceBinding{}, &micro)
+	if deployment.Name != "demo-canary" {
+		t.Errorf("Deployment.Name = %s; want 'demo-canary'", deployment.Name)
+	}
+	if *deployment.Spec.Replicas != 1 {
+		t.Errorf("Deployment.Spec.Replicas = %d; want 1", *deployment.Spec.Replicas)
+	}
+	container := findAppContainer(&deployment.Spec.Template.Spec)
+	if container.Image != "springguides/demo:v2" {
+		t.Errorf("Container.Image = %s; want 'springguides/demo:v2'", container.Image)
+	}
+	labels := deployment.Spec.Template.Labels
+	if labels["app"] != "demo" || labels["spring.io/track"] != "canary" {
+		t.Errorf("Template.Labels = %s; want 'app=demo,spring.io/track=canary'", labels)
+	}
+	if deployment.Spec.Selector.MatchLabels["spring.io/track"] != "canary" {
+		t.Errorf("Deployment.Spec.Selector = %s; want 'spring.io/track=canary'", deployment.Spec.Selector.MatchLabels)
+	}
+	stable := createDeployment([]api.ServiceBinding{}, &micro)
+	if findAppContainer(&stable.Spec.Template.Spec).Image != "springguides/demo" {
+		t.Errorf("Container.Image = %s; want 'springguides/demo'", findAppContainer(&stable.Spec.Template.Spec).Image)
+	}
+	micro.Spec.Job = true
+	if createCanary([]api.ServiceBinding{}, &micro) != nil {
+		t.Errorf("Deployment = not nil; want nil")
+	}
+}
+
+func TestCanaryOutcome(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "demo",
+			Namespace:   "test",
+			Annotations: map[string]string{"spring.io/canary-promoted": "springguides/demo:v2"},
+		},
+		Spec: api.MicroserviceSpec{
+			Image:  "springguides/demo",
+			Canary: &api.CanarySpec{Image: "springguides/demo:v2"},
+		},
+	}
+	if createCanary([]api.ServiceBinding{}, &micro) != nil {
+		t.Errorf("Deployment = not nil; want nil when the canary was promoted")
+	}
+	stable := createDeployment([]api.ServiceBinding{}, &micro)
+	if findAppContainer(&stable.Spec.Template.Spec).Image != "springguides/demo:v2" {
+		t.Errorf("Container.Image = %s; want 'springguides/demo:v2'", findAppContainer(&stable.Spec.Template.Spec).Image)
+	}
+	micro.Annotations = map[string]string{"spring.io/canary-aborted": "springguides/demo:v2"}
+	if canaryOutcome(&micro) != api.CanaryAborted {
+		t.Errorf("Outcome = %s; want 'Aborted'", canaryOutcome(&micro))
+	}
+	stable = createDeployment([]api.ServiceBinding{}, &micro)
+	if findAppContainer(&stable.Spec.Template.Spec).Image != "springguides/demo" {
+		t.Errorf("Container.Image = %s; want 'springguides/demo'", findAppContainer(&stable.Spec.Template.Spec).Image)
+	}
+	micro.Spec.Canary.Image = "springguides/demo:v3"
+	if canaryOutcome(&micro) != "" || createCanary([]api.ServiceBinding{}, &micro) == nil {
+		t.Errorf("Outcome = %s; want a new canary for a new image", canaryOutcome(&micro))
+	}
+}
+
+func TestCanaryReplicas(t *testing.T) {
+	replicas := int32(4)
+	weight := int32(20)
+	micro := api.Microservice{
+		Spec: api.MicroserviceSpec{
+			Replicas: &replicas,
+			Canary:   &api.CanarySpec{Weight: &weight},
+		},
+	}
+	if canaryReplicas(&micro) != 1 {
+		t.Errorf("canaryReplicas() = %d; want 1", canaryReplicas(&micro))
+	}
+	weight = 50
+	if canaryReplicas(&micro) != 4 {
+		t.Errorf("canaryReplicas() = %d; want 4", canaryReplicas(&micro))
+	}
+	weight = 30
+	if canaryReplicas(&micro) != 2 {
+		t.Errorf("canaryReplicas() = %d; want 2", canaryReplicas(&micro))
+	}
+	micro.Spec.Canary.Replicas = &replicas
+	if canaryReplicas(&micro) != 4 {
+		t.Errorf("canaryReplicas() = %d; want 4", canaryReplicas(&micro))
+	}
+}
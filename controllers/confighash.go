@@ -0,0 +1,166 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	"github.com/vmware-labs/reconciler-runtime/tracker"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var configHashAnnotation = "spring.io/config-hash"
+
+// ConfigHashReconciler hashes the data in the ConfigMaps and Secrets that the pod template refers
+// to, and watches them, so that a change in the data rolls the pods
+func ConfigHashReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("ConfigHash")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) error {
+			template := updatePodTemplate(&corev1.PodTemplateSpec{}, resolveBindings(c, micro), micro)
+			configMaps, secrets := configReferences(&template.Spec)
+			// The properties ConfigMap has its own hash, computed from the spec
+			delete(configMaps, propertiesName(micro))
+			hash, err := hashConfig(ctx, c, micro, configMaps, secrets)
+			if err != nil {
+				return err
+			}
+			micro.Status.ConfigHash = hash
+			return nil
+		},
+
+		Config: c,
+
+		Setup: func(ctx context.Context, mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
+			bldr.Watches(&source.Kind{Type: &corev1.ConfigMap{}}, reconcilers.EnqueueTracked(&corev1.ConfigMap{}, c.Tracker, c.Scheme()))
+			bldr.Watches(&source.Kind{Type: &corev1.Secret{}}, reconcilers.EnqueueTracked(&corev1.Secret{}, c.Tracker, c.Scheme()))
+			return nil
+		},
+	}
+}
+
+// Track the ConfigMaps and Secrets and hash their data. Missing ones are left out, so the hash changes
+// when they show up. The hash is empty if there are no references.
+func hashConfig(ctx context.Context, c reconcilers.Config, micro *api.Microservice, configMaps, secrets map[string]bool) (string, error) {
+	if len(configMaps) == 0 && len(secrets) == 0 {
+		return "", nil
+	}
+	parent := types.NamespacedName{Namespace: micro.Namespace, Name: micro.Name}
+	data := map[string]interface{}{}
+	for name := range configMaps {
+		key := types.NamespacedName{Namespace: micro.Namespace, Name: name}
+		c.Tracker.Track(tracker.NewKey(corev1.SchemeGroupVersion.WithKind("ConfigMap"), key), parent)
+		var config corev1.ConfigMap
+		if err := c.Get(ctx, client.ObjectKey(key), &config); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		data["configmap/"+name] = []interface{}{config.Data, config.BinaryData}
+	}
+	for name := range secrets {
+		key := types.NamespacedName{Namespace: micro.Namespace, Name: name}
+		c.Tracker.Track(tracker.NewKey(corev1.SchemeGroupVersion.WithKind("Secret"), key), parent)
+		var secret corev1.Secret
+		if err := c.Get(ctx, client.ObjectKey(key), &secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		data["secret/"+name] = secret.Data
+	}
+	return computeHash(data), nil
+}
+
+// The names of the ConfigMaps and Secrets that the pod spec mounts or reads environment variables from
+func configReferences(spec *corev1.PodSpec) (map[string]bool, map[string]bool) {
+	configMaps, secrets := envConfigReferences(spec)
+	for _, volume := range spec.Volumes {
+		if volume.ConfigMap != nil {
+			configMaps[volume.ConfigMap.Name] = true
+		}
+		if volume.Secret != nil {
+			secrets[volume.Secret.SecretName] = true
+		}
+		if volume.Projected != nil {
+			for _, projection := range volume.Projected.Sources {
+				if projection.ConfigMap != nil {
+					configMaps[projection.ConfigMap.Name] = true
+				}
+				if projection.Secret != nil {
+					secrets[projection.Secret.Name] = true
+				}
+			}
+		}
+	}
+	return configMaps, secrets
+}
+
+// The names of the ConfigMaps and Secrets that the containers read environment variables from
+func envConfigReferences(spec *corev1.PodSpec) (map[string]bool, map[string]bool) {
+	configMaps := map[string]bool{}
+	secrets := map[string]bool{}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, from := range container.EnvFrom {
+			if from.ConfigMapRef != nil {
+				configMaps[from.ConfigMapRef.Name] = true
+			}
+			if from.SecretRef != nil {
+				secrets[from.SecretRef.Name] = true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				configMaps[env.ValueFrom.ConfigMapKeyRef.Name] = true
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				secrets[env.ValueFrom.SecretKeyRef.Name] = true
+			}
+		}
+	}
+	return configMaps, secrets
+}
+
+// Stamp the hash of the referenced config on the pod template, so the pods roll when it changes. If the
+// pods refresh their config instead, the hash only moves on when they have to restart.
+func addConfigHash(template *corev1.PodTemplateSpec, micro *api.Microservice) {
+	hash := micro.Status.ConfigHash
+	if refreshesConfig(micro) && micro.Status.ConfigReload != nil {
+		hash = micro.Status.ConfigReload.TemplateHash
+	}
+	if hash == "" {
+		return
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[configHashAnnotation] = hash
+}
@@ -0,0 +1,109 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigReferences(t *testing.T) {
+	spec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "config"}}}},
+			{Name: "secret", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "secret"}}},
+			{Name: "projected", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "projected"}}},
+					{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "projected"}}},
+				}}}},
+		},
+		InitContainers: []corev1.Container{{
+			Name:    "init",
+			EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "init"}}}},
+		}},
+		Containers: []corev1.Container{{
+			Name:    "app",
+			EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}}}},
+			Env: []corev1.EnvVar{
+				{Name: "PLAIN", Value: "value"},
+				{Name: "URL", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "url"}}},
+				{Name: "PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}}},
+			},
+		}},
+	}
+	configMaps, secrets := configReferences(&spec)
+	if len(configMaps) != 4 || !configMaps["config"] || !configMaps["projected"] || !configMaps["env"] || !configMaps["db"] {
+		t.Errorf("ConfigMaps = %v; want 'config', 'projected', 'env', 'db'", configMaps)
+	}
+	if len(secrets) != 4 || !secrets["secret"] || !secrets["projected"] || !secrets["init"] || !secrets["db"] {
+		t.Errorf("Secrets = %v; want 'secret', 'projected', 'init', 'db'", secrets)
+	}
+}
+
+func TestConfigReferencesFromBinding(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "springguides/demo",
+		},
+	}
+	binding := defaultBinding("mysql", micro)
+	binding.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{Name: "mysql", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "mysql"}}},
+	}
+	deployment := createDeployment([]api.ServiceBinding{binding}, &micro)
+	_, secrets := configReferences(&deployment.Spec.Template.Spec)
+	if len(secrets) != 1 || !secrets["mysql"] {
+		t.Errorf("Secrets = %v; want 'mysql'", secrets)
+	}
+}
+
+func TestCreateDeploymentConfigHash(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "springguides/demo",
+		},
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	if _, ok := deployment.Spec.Template.Annotations["spring.io/config-hash"]; ok {
+		t.Errorf("Annotations = %s; want no 'spring.io/config-hash'", deployment.Spec.Template.Annotations)
+	}
+	micro.Status.ConfigHash = "abc"
+	deployment = createDeployment([]api.ServiceBinding{}, &micro)
+	if deployment.Spec.Template.Annotations["spring.io/config-hash"] != "abc" {
+		t.Errorf("Annotations = %s; want 'spring.io/config-hash=abc'", deployment.Spec.Template.Annotations)
+	}
+	hash := deployment.Annotations["spring.io/spec-hash"]
+	micro.Status.ConfigHash = "def"
+	deployment = createDeployment([]api.ServiceBinding{}, &micro)
+	if deployment.Annotations["spring.io/spec-hash"] == hash {
+		t.Errorf("Annotations = %s; want a new 'spring.io/spec-hash'", deployment.Annotations)
+	}
+}
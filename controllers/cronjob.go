@@ -0,0 +1,103 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	batch "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+// CronJobReconciler creates a new CronJob if needed
+func CronJobReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("CronJob")
+
+	return &reconcilers.ChildReconciler{
+		Config:        c,
+		ChildType:     &batch.CronJob{},
+		ChildListType: &batch.CronJobList{},
+
+		DesiredChild: func(ctx context.Context, micro *api.Microservice) (*batch.CronJob, error) {
+			if micro.Spec.Schedule == "" {
+				return nil, nil
+			}
+			return createCronJob(resolveBindings(c, micro), micro), nil
+		},
+
+		ReflectChildStatusOnParent: func(micro *api.Microservice, child *batch.CronJob, err error) {
+			if err != nil {
+				return
+			}
+			if child == nil {
+				micro.Status.LastScheduleTime = nil
+				micro.Status.LastSuccessfulTime = nil
+				return
+			}
+			reflectCronJobStatus(micro, child)
+		},
+
+		MergeBeforeUpdate: func(current, desired *batch.CronJob) {
+			current.Labels = desired.Labels
+			current.Spec = desired.Spec
+		},
+
+		SemanticEquals: func(a1, a2 *batch.CronJob) bool {
+			return equality.Semantic.DeepEqual(a1.Spec, a2.Spec) &&
+				equality.Semantic.DeepEqual(a1.Labels, a2.Labels)
+		},
+
+		Sanitize: func(child *batch.CronJob) interface{} {
+			return child.Spec
+		},
+	}
+}
+
+func createCronJob(bindings []api.ServiceBinding, micro *api.Microservice) *batch.CronJob {
+	job := createJob(bindings, micro)
+	// The CronJob names its own Jobs
+	delete(job.Spec.Template.Labels, "pod-template-hash")
+	cronJob := &batch.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    map[string]string{"app": micro.Name},
+			Name:      micro.Name,
+			Namespace: micro.Namespace,
+		},
+		Spec: batch.CronJobSpec{
+			Schedule:                   micro.Spec.Schedule,
+			ConcurrencyPolicy:          micro.Spec.ConcurrencyPolicy,
+			SuccessfulJobsHistoryLimit: micro.Spec.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     micro.Spec.FailedJobsHistoryLimit,
+			JobTemplate: batch.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": micro.Name},
+				},
+				Spec: job.Spec,
+			},
+		},
+	}
+	return cronJob
+}
+
+func reflectCronJobStatus(micro *api.Microservice, cronJob *batch.CronJob) {
+	micro.Status.Running = len(cronJob.Status.Active) > 0
+	micro.Status.LastScheduleTime = cronJob.Status.LastScheduleTime
+	micro.Status.LastSuccessfulTime = cronJob.Status.LastSuccessfulTime
+}
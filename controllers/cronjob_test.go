@@ -0,0 +1,102 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	batch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateCronJob(t *testing.T) {
+	limit := int32(2)
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nightly",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:                      "springguides/batch",
+			Schedule:                   "0 2 * * *",
+			ConcurrencyPolicy:          batch.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &limit,
+			Profiles:                   []string{"batch"},
+		},
+	}
+	cronJob := createCronJob([]api.ServiceBinding{}, &micro)
+	if cronJob.Name != "nightly" {
+		t.Errorf("CronJob.Name = %s; want 'nightly'", cronJob.Name)
+	}
+	if cronJob.Spec.Schedule != "0 2 * * *" {
+		t.Errorf("CronJob.Spec.Schedule = %s; want '0 2 * * *'", cronJob.Spec.Schedule)
+	}
+	if cronJob.Spec.ConcurrencyPolicy != batch.ForbidConcurrent {
+		t.Errorf("CronJob.Spec.ConcurrencyPolicy = %s; want 'Forbid'", cronJob.Spec.ConcurrencyPolicy)
+	}
+	if *cronJob.Spec.SuccessfulJobsHistoryLimit != 2 {
+		t.Errorf("CronJob.Spec.SuccessfulJobsHistoryLimit = %d; want 2", *cronJob.Spec.SuccessfulJobsHistoryLimit)
+	}
+	if cronJob.Spec.FailedJobsHistoryLimit != nil {
+		t.Errorf("CronJob.Spec.FailedJobsHistoryLimit = %d; want nil", *cronJob.Spec.FailedJobsHistoryLimit)
+	}
+	if cronJob.Spec.JobTemplate.Labels["app"] != "nightly" {
+		t.Errorf("JobTemplate.Labels['app'] = %s; want 'nightly'", cronJob.Spec.JobTemplate.Labels["app"])
+	}
+	template := cronJob.Spec.JobTemplate.Spec.Template
+	if template.Spec.RestartPolicy != corev1.RestartPolicyNever {
+		t.Errorf("RestartPolicy = %s; want 'Never'", template.Spec.RestartPolicy)
+	}
+	container := findAppContainer(&template.Spec)
+	if container.Image != "springguides/batch" {
+		t.Errorf("Container.Image = %s; want 'springguides/batch'", container.Image)
+	}
+	if findEnvByName(container.Env, "SPRING_PROFILES_ACTIVE").Value != "batch" {
+		t.Errorf("SPRING_PROFILES_ACTIVE = %s; want 'batch'", findEnvByName(container.Env, "SPRING_PROFILES_ACTIVE").Value)
+	}
+	if createService(&micro) != nil {
+		t.Errorf("Service = %v; want nil", createService(&micro))
+	}
+}
+
+func TestCronJobStatus(t *testing.T) {
+	micro := api.Microservice{}
+	scheduled := metav1.NewTime(time.Date(2020, 4, 1, 2, 0, 0, 0, time.UTC))
+	successful := metav1.NewTime(time.Date(2020, 3, 31, 2, 5, 0, 0, time.UTC))
+	cronJob := batch.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "nightly",
+		},
+		Status: batch.CronJobStatus{
+			Active:             []corev1.ObjectReference{{Name: "nightly-3"}},
+			LastScheduleTime:   &scheduled,
+			LastSuccessfulTime: &successful,
+		},
+	}
+	reflectCronJobStatus(&micro, &cronJob)
+	if !micro.Status.Running {
+		t.Errorf("Status.Running = false; want true")
+	}
+	if !micro.Status.LastScheduleTime.Equal(&scheduled) {
+		t.Errorf("Status.LastScheduleTime = %s; want %s", micro.Status.LastScheduleTime, scheduled)
+	}
+	if !micro.Status.LastSuccessfulTime.Equal(&successful) {
+		t.Errorf("Status.LastSuccessfulTime = %s; want %s", micro.Status.LastSuccessfulTime, successful)
+	}
+}
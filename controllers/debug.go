@@ -0,0 +1,91 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var debugPort = int32(5005)
+
+// DebugServiceReconciler creates a Service for the debug port if needed
+func DebugServiceReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("DebugService")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) error {
+			key := client.ObjectKey{Namespace: micro.Namespace, Name: debugName(micro)}
+			current, err := getOwned(ctx, c, key, &corev1.Service{})
+			if err != nil {
+				return err
+			}
+			return applyOwnedService(ctx, c, micro, current, createDebugService(micro))
+		},
+
+		Config: c,
+	}
+}
+
+func debugName(micro *api.Microservice) string {
+	return fmt.Sprintf("%s-debug", micro.Name)
+}
+
+func createDebugService(micro *api.Microservice) *corev1.Service {
+	if !micro.Spec.Debug || runsToCompletion(micro) {
+		return nil
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    map[string]string{"app": micro.Name},
+			Name:      debugName(micro),
+			Namespace: micro.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Protocol:   "TCP",
+					Port:       debugPort,
+					TargetPort: intstr.FromInt(int(debugPort)),
+					Name:       "debug",
+				},
+			},
+			Selector: serviceSelector(micro),
+		},
+	}
+	service.Annotations = map[string]string{specHashAnnotation: computeHash(service.Spec)}
+	return service
+}
+
+// Start the JDWP agent in the app container and stop the kubelet from restarting it while it is
+// paused on a breakpoint
+func addDebug(container *corev1.Container, micro *api.Microservice) {
+	if !micro.Spec.Debug {
+		return
+	}
+	appendJavaToolOptions(container, fmt.Sprintf("-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:%d", debugPort))
+	container.Ports = setContainerPort(container.Ports, "debug", debugPort)
+	container.LivenessProbe = nil
+}
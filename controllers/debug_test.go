@@ -0,0 +1,106 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+func TestCreateDeploymentDebug(t *testing.T) {
+	replicas := int32(3)
+	micro := demoMicroservice(api.MicroserviceSpec{Replicas: &replicas, Probes: &api.Probes{}})
+	micro.Spec.Debug = true
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	if *deployment.Spec.Replicas != 1 {
+		t.Errorf("Deployment.Spec.Replicas = %d; want 1", *deployment.Spec.Replicas)
+	}
+	container := findAppContainer(&deployment.Spec.Template.Spec)
+	env := findEnvByName(container.Env, "JAVA_TOOL_OPTIONS")
+	if !strings.Contains(env.Value, "-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:5005") {
+		t.Errorf("JAVA_TOOL_OPTIONS = %s; want jdwp agent", env.Value)
+	}
+	if container.Ports[len(container.Ports)-1].Name != "debug" || container.Ports[len(container.Ports)-1].ContainerPort != 5005 {
+		t.Errorf("Container.Ports = %v; want 'debug' 5005", container.Ports)
+	}
+	if container.LivenessProbe != nil {
+		t.Errorf("LivenessProbe = %v; want nil", container.LivenessProbe)
+	}
+	if container.ReadinessProbe == nil {
+		t.Errorf("ReadinessProbe = nil; want not nil")
+	}
+}
+
+func TestCreateDeploymentDebugOff(t *testing.T) {
+	replicas := int32(3)
+	micro := demoMicroservice(api.MicroserviceSpec{Replicas: &replicas, Probes: &api.Probes{}})
+	before := createDeployment([]api.ServiceBinding{}, &micro)
+	micro.Spec.Debug = true
+	createDeployment([]api.ServiceBinding{}, &micro)
+	micro.Spec.Debug = false
+	after := createDeployment([]api.ServiceBinding{}, &micro)
+	if !equality.Semantic.DeepEqual(before, after) {
+		t.Errorf("Deployment = %v; want %v", after, before)
+	}
+	if createDebugService(&micro) != nil {
+		t.Errorf("Service = not nil; want nil")
+	}
+}
+
+func TestCreateDebugService(t *testing.T) {
+	replicas := int32(3)
+	micro := demoMicroservice(api.MicroserviceSpec{Replicas: &replicas, Probes: &api.Probes{}})
+	micro.Spec.Debug = true
+	micro.Spec.Autoscaling = &api.Autoscaling{MaxReplicas: 3}
+	service := createDebugService(&micro)
+	if service.Name != "demo-debug" {
+		t.Errorf("Service.Name = %s; want 'demo-debug'", service.Name)
+	}
+	if service.Spec.Ports[0].Port != 5005 {
+		t.Errorf("Service.Spec.Ports = %v; want 5005", service.Spec.Ports)
+	}
+	if service.Spec.Selector["app"] != "demo" {
+		t.Errorf("Service.Spec.Selector = %s; want 'app=demo'", service.Spec.Selector)
+	}
+	if createAutoscaler(&micro) != nil {
+		t.Errorf("HorizontalPodAutoscaler = not nil; want nil")
+	}
+}
+
+func TestCreateDebugCanary(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{Canary: &api.CanarySpec{Image: "springguides/demo:v2"}})
+	micro.Spec.Debug = true
+	if createCanary([]api.ServiceBinding{}, &micro) != nil {
+		t.Errorf("Canary = not nil; want nil")
+	}
+	if createDebugService(&micro).Spec.Selector["app"] != "demo" {
+		t.Errorf("Service.Spec.Selector = %s; want 'app=demo'", createDebugService(&micro).Spec.Selector)
+	}
+}
+
+func TestCreateDebugServiceBlueGreen(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{BlueGreen: &api.BlueGreenSpec{}})
+	micro.Spec.Debug = true
+	micro.Annotations = map[string]string{"spring.io/active": "black"}
+	micro.Status.BlueGreen = &api.BlueGreenStatus{ActiveColor: "black", Switched: true}
+	service := createDebugService(&micro)
+	if service.Spec.Selector["spring.io/color"] != "black" {
+		t.Errorf("Service.Spec.Selector = %s; want 'spring.io/color=black'", service.Spec.Selector)
+	}
+}
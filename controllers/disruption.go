@@ -0,0 +1,139 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+// PodDisruptionBudgetReconciler creates a new PodDisruptionBudget if needed
+func PodDisruptionBudgetReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("PodDisruptionBudget")
+
+	return &reconcilers.ChildReconciler{
+		Config:        c,
+		ChildType:     &policy.PodDisruptionBudget{},
+		ChildListType: &policy.PodDisruptionBudgetList{},
+
+		DesiredChild: func(ctx context.Context, micro *api.Microservice) (*policy.PodDisruptionBudget, error) {
+			return createDisruptionBudget(micro), nil
+		},
+
+		ReflectChildStatusOnParent: func(micro *api.Microservice, child *policy.PodDisruptionBudget, err error) {
+			if err != nil {
+				return
+			}
+			if reflectDisruptionBudgetStatus(micro, child) {
+				c.Recorder.Eventf(micro, corev1.EventTypeWarning, "SingleReplica",
+					"PodDisruptionBudget %q cannot protect a single replica. Node drains will either be blocked or take out the app.", child.Name)
+			}
+		},
+
+		MergeBeforeUpdate: func(current, desired *policy.PodDisruptionBudget) {
+			current.Labels = desired.Labels
+			current.Spec = desired.Spec
+		},
+
+		SemanticEquals: func(a1, a2 *policy.PodDisruptionBudget) bool {
+			return equality.Semantic.DeepEqual(a1.Spec, a2.Spec) &&
+				equality.Semantic.DeepEqual(a1.Labels, a2.Labels)
+		},
+
+		Sanitize: func(child *policy.PodDisruptionBudget) interface{} {
+			return child.Spec
+		},
+	}
+}
+
+func createDisruptionBudget(micro *api.Microservice) *policy.PodDisruptionBudget {
+	// A debug session has only one replica, so there is nothing to protect
+	if micro.Spec.DisruptionBudget == nil || runsToCompletion(micro) || micro.Spec.Debug {
+		return nil
+	}
+	budget := &policy.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    map[string]string{"app": micro.Name},
+			Name:      micro.Name,
+			Namespace: micro.Namespace,
+		},
+		Spec: policy.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": micro.Name},
+			},
+		},
+	}
+	options := micro.Spec.DisruptionBudget
+	if options.MinAvailable != nil {
+		budget.Spec.MinAvailable = options.MinAvailable
+	} else if options.MaxUnavailable != nil {
+		budget.Spec.MaxUnavailable = options.MaxUnavailable
+	} else {
+		one := intstr.FromInt(1)
+		budget.Spec.MaxUnavailable = &one
+	}
+	return budget
+}
+
+// Record whether the budget can protect the app, returning true if it has just stopped being able to
+// (only one replica)
+func reflectDisruptionBudgetStatus(micro *api.Microservice, budget *policy.PodDisruptionBudget) bool {
+	if budget == nil {
+		meta.RemoveStatusCondition(&micro.Status.Conditions, api.MicroserviceDisruptionBudgetEffective)
+		return false
+	}
+	if minimumReplicas(micro) < 2 {
+		changed := !meta.IsStatusConditionFalse(micro.Status.Conditions, api.MicroserviceDisruptionBudgetEffective)
+		meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+			Type:    api.MicroserviceDisruptionBudgetEffective,
+			Status:  metav1.ConditionFalse,
+			Reason:  "SingleReplica",
+			Message: fmt.Sprintf("PodDisruptionBudget %q cannot protect a single replica", budget.Name),
+		})
+		return changed
+	}
+	meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+		Type:    api.MicroserviceDisruptionBudgetEffective,
+		Status:  metav1.ConditionTrue,
+		Reason:  "MultipleReplicas",
+		Message: "",
+	})
+	return false
+}
+
+// The smallest number of replicas the Deployment is expected to have
+func minimumReplicas(micro *api.Microservice) int32 {
+	if micro.Spec.Autoscaling != nil {
+		if micro.Spec.Autoscaling.MinReplicas != nil {
+			return *micro.Spec.Autoscaling.MinReplicas
+		}
+		return 1
+	}
+	if micro.Spec.Replicas != nil {
+		return *micro.Spec.Replicas
+	}
+	return 1
+}
@@ -0,0 +1,133 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestCreateDisruptionBudget(t *testing.T) {
+	min := intstr.FromString("50%")
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:            "springguides/demo",
+			DisruptionBudget: &api.DisruptionBudget{MinAvailable: &min},
+		},
+	}
+	budget := createDisruptionBudget(&micro)
+	if budget == nil {
+		t.Fatalf("PodDisruptionBudget = nil; want not nil")
+	}
+	if budget.Name != "demo" {
+		t.Errorf("PodDisruptionBudget.Name = %s; want 'demo'", budget.Name)
+	}
+	if budget.Spec.Selector.MatchLabels["app"] != "demo" {
+		t.Errorf("PodDisruptionBudget.Spec.Selector = %s; want 'app=demo'", budget.Spec.Selector.MatchLabels)
+	}
+	if budget.Spec.MinAvailable.String() != "50%" {
+		t.Errorf("PodDisruptionBudget.Spec.MinAvailable = %s; want '50%%'", budget.Spec.MinAvailable.String())
+	}
+	if budget.Spec.MaxUnavailable != nil {
+		t.Errorf("PodDisruptionBudget.Spec.MaxUnavailable = %s; want nil", budget.Spec.MaxUnavailable.String())
+	}
+}
+
+func TestCreateDisruptionBudgetDefault(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:            "springguides/demo",
+			DisruptionBudget: &api.DisruptionBudget{},
+		},
+	}
+	budget := createDisruptionBudget(&micro)
+	if budget.Spec.MaxUnavailable.IntValue() != 1 {
+		t.Errorf("PodDisruptionBudget.Spec.MaxUnavailable = %s; want '1'", budget.Spec.MaxUnavailable.String())
+	}
+	micro.Spec.Debug = true
+	if createDisruptionBudget(&micro) != nil {
+		t.Errorf("PodDisruptionBudget = not nil; want nil")
+	}
+	micro.Spec.Debug = false
+	micro.Spec.DisruptionBudget = nil
+	if createDisruptionBudget(&micro) != nil {
+		t.Errorf("PodDisruptionBudget = not nil; want nil")
+	}
+}
+
+func TestDisruptionBudgetStatus(t *testing.T) {
+	two := int32(2)
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:            "springguides/demo",
+			DisruptionBudget: &api.DisruptionBudget{},
+		},
+	}
+	budget := createDisruptionBudget(&micro)
+	if !reflectDisruptionBudgetStatus(&micro, budget) {
+		t.Errorf("reflectDisruptionBudgetStatus() = false; want true")
+	}
+	if !meta.IsStatusConditionFalse(micro.Status.Conditions, api.MicroserviceDisruptionBudgetEffective) {
+		t.Errorf("Status.Conditions = %v; want 'DisruptionBudgetEffective' false", micro.Status.Conditions)
+	}
+	// Only warn once
+	if reflectDisruptionBudgetStatus(&micro, budget) {
+		t.Errorf("reflectDisruptionBudgetStatus() = true; want false")
+	}
+	micro.Spec.Replicas = &two
+	if reflectDisruptionBudgetStatus(&micro, budget) {
+		t.Errorf("reflectDisruptionBudgetStatus() = true; want false")
+	}
+	if !meta.IsStatusConditionTrue(micro.Status.Conditions, api.MicroserviceDisruptionBudgetEffective) {
+		t.Errorf("Status.Conditions = %v; want 'DisruptionBudgetEffective' true", micro.Status.Conditions)
+	}
+	reflectDisruptionBudgetStatus(&micro, nil)
+	if len(micro.Status.Conditions) != 0 {
+		t.Errorf("len(Status.Conditions) = %d; want 0", len(micro.Status.Conditions))
+	}
+}
+
+func TestMinimumReplicas(t *testing.T) {
+	two := int32(2)
+	micro := api.Microservice{}
+	if minimumReplicas(&micro) != 1 {
+		t.Errorf("minimumReplicas() = %d; want 1", minimumReplicas(&micro))
+	}
+	micro.Spec.Replicas = &two
+	if minimumReplicas(&micro) != 2 {
+		t.Errorf("minimumReplicas() = %d; want 2", minimumReplicas(&micro))
+	}
+	micro.Spec.Autoscaling = &api.Autoscaling{MaxReplicas: 4}
+	if minimumReplicas(&micro) != 1 {
+		t.Errorf("minimumReplicas() = %d; want 1", minimumReplicas(&micro))
+	}
+}
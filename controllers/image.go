@@ -0,0 +1,165 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var resolveImageAnnotation = "spring.io/resolve-image"
+var imageRetryPeriod = time.Minute
+
+// ImageReconciler looks up the digest of the image in the registry, so that all the pods run the same
+// bits even if the tag moves
+func ImageReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("Image")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) (ctrl.Result, error) {
+			options := micro.Spec.ImageResolution
+			if options == nil {
+				micro.Status.Image = nil
+				meta.RemoveStatusCondition(&micro.Status.Conditions, api.MicroserviceImageResolved)
+				return ctrl.Result{}, nil
+			}
+			now := metav1.Now()
+			trigger := micro.Annotations[resolveImageAnnotation]
+			if due, wait := imageResolutionDue(micro, now.Time); !due {
+				return ctrl.Result{RequeueAfter: wait}, nil
+			}
+			ref, err := parseImageReference(micro.Spec.Image)
+			if err != nil {
+				micro.Status.Image = nil
+				meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+					Type:    api.MicroserviceImageResolved,
+					Status:  metav1.ConditionFalse,
+					Reason:  "InvalidImage",
+					Message: err.Error(),
+				})
+				return ctrl.Result{}, nil
+			}
+			credentials := imageCredentials(ctx, c, micro, ref)
+			digest, err := resolveDigest(ctx, ref, credentials, options.Insecure)
+			if err != nil {
+				// Keep the digest from before, if it was for the same image, and try again later
+				if micro.Status.Image != nil && micro.Status.Image.Image != micro.Spec.Image {
+					micro.Status.Image = nil
+				}
+				meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+					Type:    api.MicroserviceImageResolved,
+					Status:  metav1.ConditionFalse,
+					Reason:  "ResolutionFailed",
+					Message: err.Error(),
+				})
+				return ctrl.Result{RequeueAfter: imageRetryPeriod}, nil
+			}
+			if micro.Status.Image == nil || micro.Status.Image.Digest != digest {
+				c.Recorder.Eventf(micro, corev1.EventTypeNormal, "ImageResolved", "Resolved image %q to %s", micro.Spec.Image, digest)
+			}
+			micro.Status.Image = &api.ImageStatus{
+				Image:            micro.Spec.Image,
+				Digest:           digest,
+				LastResolvedTime: &now,
+				Trigger:          trigger,
+			}
+			meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+				Type:    api.MicroserviceImageResolved,
+				Status:  metav1.ConditionTrue,
+				Reason:  "Resolved",
+				Message: digest,
+			})
+			if options.Interval != nil {
+				return ctrl.Result{RequeueAfter: options.Interval.Duration}, nil
+			}
+			return ctrl.Result{}, nil
+		},
+
+		Config: c,
+	}
+}
+
+// The image needs resolving if it changed, the annotation changed or the interval is up. If not, the
+// time until the next one is due, or zero if there isn't one.
+func imageResolutionDue(micro *api.Microservice, now time.Time) (bool, time.Duration) {
+	status := micro.Status.Image
+	if status == nil || status.Image != micro.Spec.Image || status.Trigger != micro.Annotations[resolveImageAnnotation] ||
+		status.LastResolvedTime == nil {
+		return true, 0
+	}
+	interval := micro.Spec.ImageResolution.Interval
+	if interval == nil || interval.Duration <= 0 {
+		return false, 0
+	}
+	next := status.LastResolvedTime.Add(interval.Duration)
+	if !now.Before(next) {
+		return true, 0
+	}
+	return false, next.Sub(now)
+}
+
+// Credentials for the registry from the first image pull secret that has some
+func imageCredentials(ctx context.Context, c reconcilers.Config, micro *api.Microservice, ref imageReference) *registryCredentials {
+	secrets := append([]corev1.LocalObjectReference{}, micro.Spec.ImagePullSecrets...)
+	secrets = append(secrets, micro.Spec.Template.Spec.ImagePullSecrets...)
+	for _, reference := range secrets {
+		var secret corev1.Secret
+		key := types.NamespacedName{Namespace: micro.Namespace, Name: reference.Name}
+		if found, err := exists(ctx, c, key, &secret); err != nil || !found {
+			continue
+		}
+		for _, name := range []string{corev1.DockerConfigJsonKey, corev1.DockerConfigKey} {
+			if data, ok := secret.Data[name]; ok {
+				if credentials := findRegistryCredentials(data, ref.Registry); credentials != nil {
+					return credentials
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// The image for the app container, pinned to the resolved digest if there is one (or the canary image
+// if it has been promoted)
+func appImage(micro api.Microservice) string {
+	if canaryOutcome(&micro) == api.CanaryPromoted {
+		return micro.Spec.Canary.Image
+	}
+	status := micro.Status.Image
+	if micro.Spec.ImageResolution == nil || status == nil || status.Image != micro.Spec.Image || status.Digest == "" {
+		return micro.Spec.Image
+	}
+	name := micro.Spec.Image
+	if index := strings.Index(name, "@"); index >= 0 {
+		return name
+	}
+	if index := strings.LastIndex(name, ":"); index > strings.LastIndex(name, "/") {
+		name = name[:index]
+	}
+	return fmt.Sprintf("%s@%s", name, status.Digest)
+}
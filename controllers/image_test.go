@@ -0,0 +1,86 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateDeploymentResolvedImage(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:           "localhost:5000/demo:v1",
+			ImageResolution: &api.ImageResolution{},
+		},
+		Status: api.MicroserviceStatus{
+			Image: &api.ImageStatus{Image: "localhost:5000/demo:v1", Digest: "sha256:1234"},
+		},
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	if image := findAppContainer(&deployment.Spec.Template.Spec).Image; image != "localhost:5000/demo@sha256:1234" {
+		t.Errorf("Image = %s; want 'localhost:5000/demo@sha256:1234'", image)
+	}
+	micro.Spec.Image = "localhost:5000/demo:v2"
+	deployment = createDeployment([]api.ServiceBinding{}, &micro)
+	if image := findAppContainer(&deployment.Spec.Template.Spec).Image; image != "localhost:5000/demo:v2" {
+		t.Errorf("Image = %s; want 'localhost:5000/demo:v2'", image)
+	}
+	micro.Spec.Image = "localhost:5000/demo:v1"
+	micro.Spec.ImageResolution = nil
+	deployment = createDeployment([]api.ServiceBinding{}, &micro)
+	if image := findAppContainer(&deployment.Spec.Template.Spec).Image; image != "localhost:5000/demo:v1" {
+		t.Errorf("Image = %s; want 'localhost:5000/demo:v1'", image)
+	}
+}
+
+func TestImageResolutionDue(t *testing.T) {
+	now := time.Now()
+	resolved := metav1.NewTime(now.Add(-5 * time.Minute))
+	micro := api.Microservice{
+		Spec: api.MicroserviceSpec{
+			Image:           "springguides/demo",
+			ImageResolution: &api.ImageResolution{},
+		},
+	}
+	if due, _ := imageResolutionDue(&micro, now); !due {
+		t.Errorf("due = false; want true with no status")
+	}
+	micro.Status.Image = &api.ImageStatus{Image: "springguides/demo", Digest: "sha256:1234", LastResolvedTime: &resolved}
+	if due, wait := imageResolutionDue(&micro, now); due || wait != 0 {
+		t.Errorf("due, wait = %v, %s; want false, 0", due, wait)
+	}
+	micro.Spec.ImageResolution.Interval = &metav1.Duration{Duration: 10 * time.Minute}
+	if due, wait := imageResolutionDue(&micro, now); due || wait != 5*time.Minute {
+		t.Errorf("due, wait = %v, %s; want false, 5m", due, wait)
+	}
+	micro.Spec.ImageResolution.Interval.Duration = time.Minute
+	if due, _ := imageResolutionDue(&micro, now); !due {
+		t.Errorf("due = false; want true after the interval")
+	}
+	micro.Spec.ImageResolution.Interval = nil
+	micro.Annotations = map[string]string{"spring.io/resolve-image": "now"}
+	if due, _ := imageResolutionDue(&micro, now); !due {
+		t.Errorf("due = false; want true with a new annotation")
+	}
+}
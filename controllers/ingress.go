@@ -0,0 +1,164 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+// IngressReconciler creates a new Ingress if needed
+func IngressReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("Ingress")
+
+	return &reconcilers.ChildReconciler{
+		Config:        c,
+		ChildType:     &networking.Ingress{},
+		ChildListType: &networking.IngressList{},
+
+		DesiredChild: func(ctx context.Context, micro *api.Microservice) (*networking.Ingress, error) {
+			return createIngress(micro), nil
+		},
+
+		ReflectChildStatusOnParent: func(micro *api.Microservice, child *networking.Ingress, err error) {
+			if err != nil {
+				return
+			}
+			micro.Status.URL = ingressURL(child)
+		},
+
+		MergeBeforeUpdate: func(current, desired *networking.Ingress) {
+			current.Labels = desired.Labels
+			// Ingress controllers and cert-manager add annotations of their own
+			current.Annotations = reconcilers.MergeMaps(current.Annotations, desired.Annotations)
+			current.Spec = desired.Spec
+		},
+
+		SemanticEquals: func(a1, a2 *networking.Ingress) bool {
+			return equality.Semantic.DeepEqual(a1.Spec, a2.Spec) &&
+				equality.Semantic.DeepEqual(a1.Labels, a2.Labels) &&
+				hasAnnotations(a2.Annotations, a1.Annotations)
+		},
+
+		Sanitize: func(child *networking.Ingress) interface{} {
+			return child.Spec
+		},
+	}
+}
+
+func createIngress(micro *api.Microservice) *networking.Ingress {
+	service := createService(micro)
+	if micro.Spec.Ingress == nil || service == nil {
+		return nil
+	}
+	options := micro.Spec.Ingress
+	annotations := reconcilers.MergeMaps(options.Annotations)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	ingress := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"app": micro.Name},
+			Annotations: annotations,
+			Name:        micro.Name,
+			Namespace:   micro.Namespace,
+		},
+	}
+	if options.ClassName != "" {
+		className := options.ClassName
+		ingress.Spec.IngressClassName = &className
+	}
+	paths := options.Paths
+	if len(paths) == 0 {
+		paths = []string{"/"}
+	}
+	http := &networking.HTTPIngressRuleValue{}
+	pathType := networking.PathTypePrefix
+	for _, path := range paths {
+		http.Paths = append(http.Paths, networking.HTTPIngressPath{
+			Path:     path,
+			PathType: &pathType,
+			Backend: networking.IngressBackend{
+				Service: &networking.IngressServiceBackend{
+					Name: service.Name,
+					Port: networking.ServiceBackendPort{Name: "http"},
+				},
+			},
+		})
+	}
+	hosts := options.Hosts
+	if len(hosts) == 0 {
+		// A rule with no host matches all traffic
+		hosts = []string{""}
+	}
+	for _, host := range hosts {
+		ingress.Spec.Rules = append(ingress.Spec.Rules, networking.IngressRule{
+			Host: host,
+			IngressRuleValue: networking.IngressRuleValue{
+				HTTP: http.DeepCopy(),
+			},
+		})
+	}
+	if options.TLSSecretName != "" {
+		ingress.Spec.TLS = []networking.IngressTLS{
+			{
+				Hosts:      options.Hosts,
+				SecretName: options.TLSSecretName,
+			},
+		}
+	}
+	return ingress
+}
+
+// The URL of the first host and path in the Ingress, or the load balancer address if there is no host
+func ingressURL(ingress *networking.Ingress) string {
+	if ingress == nil || len(ingress.Spec.Rules) == 0 {
+		return ""
+	}
+	rule := ingress.Spec.Rules[0]
+	host := rule.Host
+	if host == "" {
+		for _, balancer := range ingress.Status.LoadBalancer.Ingress {
+			if balancer.Hostname != "" {
+				host = balancer.Hostname
+			} else {
+				host = balancer.IP
+			}
+			if host != "" {
+				break
+			}
+		}
+	}
+	if host == "" {
+		return ""
+	}
+	scheme := "http"
+	if len(ingress.Spec.TLS) > 0 {
+		scheme = "https"
+	}
+	path := "/"
+	if rule.HTTP != nil && len(rule.HTTP.Paths) > 0 && rule.HTTP.Paths[0].Path != "" {
+		path = rule.HTTP.Paths[0].Path
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, host, path)
+}
@@ -0,0 +1,111 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateIngress(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "springguides/demo",
+			Ingress: &api.IngressSpec{
+				Hosts:         []string{"demo.example.com"},
+				Paths:         []string{"/api", "/ui"},
+				ClassName:     "nginx",
+				TLSSecretName: "demo-tls",
+				Annotations:   map[string]string{"foo": "bar"},
+			},
+		},
+	}
+	ingress := createIngress(&micro)
+	if ingress.Name != "demo" {
+		t.Errorf("Ingress.Name = %s; want 'demo'", ingress.Name)
+	}
+	if ingress.Spec.IngressClassName == nil || *ingress.Spec.IngressClassName != "nginx" {
+		t.Errorf("Ingress.Spec.IngressClassName = %v; want 'nginx'", ingress.Spec.IngressClassName)
+	}
+	if ingress.Annotations["foo"] != "bar" {
+		t.Errorf("Ingress.Annotations['foo'] = %s; want 'bar'", ingress.Annotations["foo"])
+	}
+	if len(ingress.Spec.Rules) != 1 {
+		t.Fatalf("len(Ingress.Spec.Rules) = %d; want 1", len(ingress.Spec.Rules))
+	}
+	rule := ingress.Spec.Rules[0]
+	if rule.Host != "demo.example.com" {
+		t.Errorf("Rule.Host = %s; want 'demo.example.com'", rule.Host)
+	}
+	if len(rule.HTTP.Paths) != 2 {
+		t.Fatalf("len(Rule.HTTP.Paths) = %d; want 2", len(rule.HTTP.Paths))
+	}
+	path := rule.HTTP.Paths[1]
+	if path.PathType == nil || *path.PathType != networking.PathTypePrefix {
+		t.Errorf("Path.PathType = %v; want 'Prefix'", path.PathType)
+	}
+	backend := path.Backend.Service
+	if backend == nil || backend.Name != "demo" || backend.Port.Name != "http" {
+		t.Errorf("Backend = %v; want 'demo:http'", backend)
+	}
+	if len(ingress.Spec.TLS) != 1 || ingress.Spec.TLS[0].SecretName != "demo-tls" {
+		t.Errorf("Ingress.Spec.TLS = %v; want 'demo-tls'", ingress.Spec.TLS)
+	}
+	if url := ingressURL(ingress); url != "https://demo.example.com/api" {
+		t.Errorf("URL = %s; want 'https://demo.example.com/api'", url)
+	}
+}
+
+func TestCreateIngressNoHost(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "demo",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:   "springguides/demo",
+			Ingress: &api.IngressSpec{},
+		},
+	}
+	ingress := createIngress(&micro)
+	if len(ingress.Spec.Rules) != 1 || ingress.Spec.Rules[0].Host != "" {
+		t.Fatalf("Ingress.Spec.Rules = %v; want one rule with no host", ingress.Spec.Rules)
+	}
+	if ingress.Annotations != nil {
+		t.Errorf("Ingress.Annotations = %v; want nil", ingress.Annotations)
+	}
+	if ingress.Spec.IngressClassName != nil {
+		t.Errorf("Ingress.Spec.IngressClassName = %v; want nil", *ingress.Spec.IngressClassName)
+	}
+	if url := ingressURL(ingress); url != "" {
+		t.Errorf("URL = %s; want ''", url)
+	}
+	ingress.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if url := ingressURL(ingress); url != "http://10.0.0.1/" {
+		t.Errorf("URL = %s; want 'http://10.0.0.1/'", url)
+	}
+	micro.Spec.Service = &api.ServiceSpec{Disabled: true}
+	if ingress := createIngress(&micro); ingress != nil {
+		t.Errorf("Ingress = %v; want nil", ingress)
+	}
+}
@@ -0,0 +1,241 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	batch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var defaultJobHistoryLimit = 3
+
+// JobReconciler creates a new Job whenever the rendered pod template changes, and prunes old runs
+func JobReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("Job")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) error {
+			var jobs batch.JobList
+			if err := c.List(ctx, &jobs, client.InNamespace(micro.Namespace), client.MatchingLabels{"app": micro.Name}); err != nil {
+				return err
+			}
+			owned := []batch.Job{}
+			for _, job := range jobs.Items {
+				if metav1.IsControlledBy(&job, micro) {
+					owned = append(owned, job)
+				}
+			}
+			current := ""
+			if micro.Spec.Job && micro.Spec.Schedule == "" {
+				desired := createJob(resolveBindings(c, micro), micro)
+				current = desired.Name
+				if findJob(owned, current) == nil {
+					if err := ctrl.SetControllerReference(micro, desired, c.Scheme()); err != nil {
+						return err
+					}
+					c.Log.Info("creating job", "job", current)
+					if err := c.Create(ctx, desired); err != nil {
+						if !apierrors.IsAlreadyExists(err) {
+							c.Recorder.Eventf(micro, corev1.EventTypeWarning, "CreationFailed",
+								"Failed to create Job %q: %v", current, err)
+							return err
+						}
+						// The cache has not caught up with the Job created on the last turn
+					} else {
+						c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Created", "Created Job %q", current)
+					}
+					owned = append(owned, *desired)
+				}
+			}
+			keep, prune := selectJobs(owned, current, jobHistoryLimit(micro))
+			for _, job := range prune {
+				c.Log.Info("deleting old job", "job", job.Name)
+				if err := c.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+					c.Recorder.Eventf(micro, corev1.EventTypeWarning, "DeleteFailed",
+						"Failed to delete Job %q: %v", job.Name, err)
+					return err
+				}
+				c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Deleted", "Deleted Job %q", job.Name)
+			}
+			reflectJobRuns(micro, current, keep)
+			return nil
+		},
+
+		Config: c,
+
+		Setup: func(ctx context.Context, mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
+			bldr.Owns(&batch.Job{})
+			return nil
+		},
+	}
+}
+
+func createJob(bindings []api.ServiceBinding, micro *api.Microservice) *batch.Job {
+	job := &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    map[string]string{"app": micro.Name},
+			Name:      micro.Name,
+			Namespace: micro.Namespace,
+		},
+		Spec: batch.JobSpec{
+			Template: corev1.PodTemplateSpec{},
+		},
+	}
+	job.Spec.Template = *updatePodTemplate(&job.Spec.Template, bindings, micro)
+	if job.Spec.Template.Spec.RestartPolicy == "" {
+		// Jobs do not accept the default "Always"
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+	// A change in the referenced config is not a reason to run the Job again
+	delete(job.Spec.Template.Annotations, configHashAnnotation)
+	// Jobs are immutable, so a change in the template needs a new one with a different name
+	hash := computeHash(&job.Spec.Template)
+	job.Name = fmt.Sprintf("%s-%s", micro.Name, hash)
+	job.Labels["pod-template-hash"] = hash
+	job.Spec.Template.Labels["pod-template-hash"] = hash
+	return job
+}
+
+// Compute a short, name-safe hash of an object (e.g. a pod template)
+func computeHash(obj interface{}) string {
+	hasher := fnv.New32a()
+	data, _ := json.Marshal(obj)
+	hasher.Write(data)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+func jobHistoryLimit(micro *api.Microservice) int {
+	if micro.Spec.JobHistoryLimit != nil {
+		return int(*micro.Spec.JobHistoryLimit)
+	}
+	return defaultJobHistoryLimit
+}
+
+func findJob(jobs []batch.Job, name string) *batch.Job {
+	for index, job := range jobs {
+		if job.Name == name {
+			return &jobs[index]
+		}
+	}
+	return nil
+}
+
+// Split the jobs into the ones to keep (current first, then newest to oldest) and the ones to delete
+func selectJobs(jobs []batch.Job, current string, limit int) ([]batch.Job, []batch.Job) {
+	keep := []batch.Job{}
+	old := []batch.Job{}
+	for _, job := range jobs {
+		if job.Name == current {
+			keep = append(keep, job)
+		} else {
+			old = append(old, job)
+		}
+	}
+	if current == "" {
+		return keep, old
+	}
+	sort.SliceStable(old, func(i, j int) bool {
+		return old[j].CreationTimestamp.Before(&old[i].CreationTimestamp)
+	})
+	if len(old) > limit {
+		return append(keep, old[:limit]...), old[limit:]
+	}
+	return append(keep, old...), []batch.Job{}
+}
+
+func reflectJobRuns(micro *api.Microservice, current string, jobs []batch.Job) {
+	var runs []api.MicroserviceRun
+	for _, job := range jobs {
+		runs = append(runs, api.MicroserviceRun{
+			Name:           job.Name,
+			Outcome:        jobOutcome(&job),
+			StartTime:      job.Status.StartTime,
+			CompletionTime: job.Status.CompletionTime,
+		})
+	}
+	micro.Status.Runs = runs
+	reflectJobStatus(micro, findJob(jobs, current))
+}
+
+func jobOutcome(job *batch.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batch.JobComplete:
+			return "Succeeded"
+		case batch.JobFailed:
+			return "Failed"
+		}
+	}
+	return "Running"
+}
+
+func reflectJobStatus(micro *api.Microservice, job *batch.Job) {
+	micro.Status.Complete = false
+	if job == nil {
+		meta.RemoveStatusCondition(&micro.Status.Conditions, api.MicroserviceSucceeded)
+		return
+	}
+	micro.Status.Running = job.Status.Active > 0
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batch.JobComplete:
+			micro.Status.Complete = true
+			meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+				Type:    api.MicroserviceSucceeded,
+				Status:  metav1.ConditionTrue,
+				Reason:  "Complete",
+				Message: condition.Message,
+			})
+			return
+		case batch.JobFailed:
+			meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+				Type:    api.MicroserviceSucceeded,
+				Status:  metav1.ConditionFalse,
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			})
+			return
+		}
+	}
+	meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+		Type:    api.MicroserviceSucceeded,
+		Status:  metav1.ConditionUnknown,
+		Reason:  "Running",
+		Message: "",
+	})
+}
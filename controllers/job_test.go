@@ -0,0 +1,268 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	batch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateJob(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "busybox",
+			Job:   true,
+			Args:  []string{"/bin/sh", "-c", "env"},
+		},
+	}
+	job := createJob([]api.ServiceBinding{defaultBinding("actuators", micro)}, &micro)
+	hash := job.Labels["pod-template-hash"]
+	if hash == "" {
+		t.Errorf("Job.Labels['pod-template-hash'] = ''; want a hash")
+	}
+	if job.Name != "job-"+hash {
+		t.Errorf("Job.Name = %s; want 'job-%s'", job.Name, hash)
+	}
+	if job.Labels["app"] != "job" {
+		t.Errorf("Job.Labels['app'] = %s; want 'job'", job.Labels["app"])
+	}
+	if job.Spec.Template.Labels["app"] != "job" {
+		t.Errorf("Job.Spec.Template.Labels['app'] = %s; want 'job'", job.Spec.Template.Labels["app"])
+	}
+	if job.Spec.Template.Spec.RestartPolicy != corev1.RestartPolicyNever {
+		t.Errorf("RestartPolicy = %s; want 'Never'", job.Spec.Template.Spec.RestartPolicy)
+	}
+	container := findAppContainer(&job.Spec.Template.Spec)
+	if container.Image != "busybox" {
+		t.Errorf("Container.Image = %s; want 'busybox'", container.Image)
+	}
+	if len(container.Args) != 3 {
+		t.Errorf("len(Container.Args) = %d; want 3", len(container.Args))
+	}
+	if container.LivenessProbe == nil {
+		t.Errorf("Container.LivenessProbe = nil; want binding probe")
+	}
+}
+
+func TestCreateJobRestartPolicy(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "job",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "busybox",
+			Job:   true,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyOnFailure,
+				},
+			},
+		},
+	}
+	job := createJob([]api.ServiceBinding{}, &micro)
+	if job.Spec.Template.Spec.RestartPolicy != corev1.RestartPolicyOnFailure {
+		t.Errorf("RestartPolicy = %s; want 'OnFailure'", job.Spec.Template.Spec.RestartPolicy)
+	}
+}
+
+func TestCreateJobHash(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "job",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "busybox",
+			Job:   true,
+		},
+	}
+	binding := defaultBinding("mysql", micro)
+	binding.Spec.Env = setEnvVars(binding.Spec.Env, "FOO", "foo")
+	binding.Spec.Env = setEnvVars(binding.Spec.Env, "BAR", "bar")
+	first := createJob([]api.ServiceBinding{binding}, &micro)
+	for i := 0; i < 10; i++ {
+		again := createJob([]api.ServiceBinding{binding}, &micro)
+		if again.Name != first.Name {
+			t.Fatalf("Job.Name = %s; want '%s'", again.Name, first.Name)
+		}
+	}
+	micro.Spec.Image = "busybox:latest"
+	changed := createJob([]api.ServiceBinding{binding}, &micro)
+	if changed.Name == first.Name {
+		t.Errorf("Job.Name = %s; want a new name when the image changes", changed.Name)
+	}
+	unbound := createJob([]api.ServiceBinding{}, &micro)
+	if unbound.Name == changed.Name {
+		t.Errorf("Job.Name = %s; want a new name when the bindings change", unbound.Name)
+	}
+	micro.Status.ConfigHash = "abc"
+	configured := createJob([]api.ServiceBinding{}, &micro)
+	if configured.Name != unbound.Name {
+		t.Errorf("Job.Name = %s; want '%s' (config changes do not re-run the Job)", configured.Name, unbound.Name)
+	}
+}
+
+func jobCreatedAt(name string, created time.Time) batch.Job {
+	return batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+	}
+}
+
+func TestSelectJobs(t *testing.T) {
+	start := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	jobs := []batch.Job{
+		jobCreatedAt("job-a", start),
+		jobCreatedAt("job-c", start.Add(2*time.Hour)),
+		jobCreatedAt("job-b", start.Add(time.Hour)),
+		{ObjectMeta: metav1.ObjectMeta{Name: "job-d"}},
+	}
+	keep, prune := selectJobs(jobs, "job-d", 2)
+	if len(keep) != 3 {
+		t.Fatalf("len(keep) = %d; want 3", len(keep))
+	}
+	if keep[0].Name != "job-d" || keep[1].Name != "job-c" || keep[2].Name != "job-b" {
+		t.Errorf("keep = [%s %s %s]; want [job-d job-c job-b]", keep[0].Name, keep[1].Name, keep[2].Name)
+	}
+	if len(prune) != 1 || prune[0].Name != "job-a" {
+		t.Errorf("prune = %v; want [job-a]", prune)
+	}
+	keep, prune = selectJobs(jobs, "", 2)
+	if len(keep) != 0 {
+		t.Errorf("len(keep) = %d; want 0", len(keep))
+	}
+	if len(prune) != 4 {
+		t.Errorf("len(prune) = %d; want 4", len(prune))
+	}
+}
+
+func TestJobRuns(t *testing.T) {
+	micro := api.Microservice{}
+	jobs := []batch.Job{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "job-b"},
+			Status:     batch.JobStatus{Active: 1},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "job-a"},
+			Status: batch.JobStatus{
+				Conditions: []batch.JobCondition{
+					{
+						Type:   batch.JobFailed,
+						Status: corev1.ConditionTrue,
+					},
+				},
+			},
+		},
+	}
+	reflectJobRuns(&micro, "job-b", jobs)
+	if len(micro.Status.Runs) != 2 {
+		t.Fatalf("len(Status.Runs) = %d; want 2", len(micro.Status.Runs))
+	}
+	if micro.Status.Runs[0].Outcome != "Running" {
+		t.Errorf("Runs[0].Outcome = %s; want 'Running'", micro.Status.Runs[0].Outcome)
+	}
+	if micro.Status.Runs[1].Outcome != "Failed" {
+		t.Errorf("Runs[1].Outcome = %s; want 'Failed'", micro.Status.Runs[1].Outcome)
+	}
+	if !micro.Status.Running {
+		t.Errorf("Status.Running = false; want true")
+	}
+	reflectJobRuns(&micro, "", []batch.Job{})
+	if micro.Status.Runs != nil {
+		t.Errorf("Status.Runs = %v; want nil", micro.Status.Runs)
+	}
+}
+
+func TestJobStatus(t *testing.T) {
+	micro := api.Microservice{}
+	job := batch.Job{
+		Status: batch.JobStatus{
+			Active: 1,
+		},
+	}
+	reflectJobStatus(&micro, &job)
+	if !micro.Status.Running {
+		t.Errorf("Status.Running = false; want true")
+	}
+	condition := meta.FindStatusCondition(micro.Status.Conditions, api.MicroserviceSucceeded)
+	if condition == nil || condition.Status != metav1.ConditionUnknown {
+		t.Errorf("Succeeded = %v; want 'Unknown'", condition)
+	}
+	job.Status.Active = 0
+	job.Status.Conditions = []batch.JobCondition{
+		{
+			Type:   batch.JobComplete,
+			Status: corev1.ConditionTrue,
+		},
+	}
+	reflectJobStatus(&micro, &job)
+	if !micro.Status.Complete {
+		t.Errorf("Status.Complete = false; want true")
+	}
+	if micro.Status.Running {
+		t.Errorf("Status.Running = true; want false")
+	}
+	condition = meta.FindStatusCondition(micro.Status.Conditions, api.MicroserviceSucceeded)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Errorf("Succeeded = %v; want 'True'", condition)
+	}
+	reflectJobStatus(&micro, nil)
+	if micro.Status.Complete {
+		t.Errorf("Status.Complete = true; want false")
+	}
+	if len(micro.Status.Conditions) != 0 {
+		t.Errorf("len(Status.Conditions) = %d; want 0", len(micro.Status.Conditions))
+	}
+}
+
+func TestJobStatusFailed(t *testing.T) {
+	micro := api.Microservice{}
+	job := batch.Job{
+		Status: batch.JobStatus{
+			Conditions: []batch.JobCondition{
+				{
+					Type:    batch.JobFailed,
+					Status:  corev1.ConditionTrue,
+					Reason:  "BackoffLimitExceeded",
+					Message: "Job has reached the specified backoff limit",
+				},
+			},
+		},
+	}
+	reflectJobStatus(&micro, &job)
+	if micro.Status.Complete {
+		t.Errorf("Status.Complete = true; want false")
+	}
+	condition := meta.FindStatusCondition(micro.Status.Conditions, api.MicroserviceSucceeded)
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		t.Fatalf("Succeeded = %v; want 'False'", condition)
+	}
+	if condition.Reason != "BackoffLimitExceeded" {
+		t.Errorf("Succeeded.Reason = %s; want 'BackoffLimitExceeded'", condition.Reason)
+	}
+}
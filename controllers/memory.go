@@ -0,0 +1,230 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+const (
+	kilobyte = int64(1024)
+	megabyte = 1024 * kilobyte
+	gigabyte = 1024 * megabyte
+)
+
+var defaultThreadCount = int32(250)
+var defaultLoadedClassCount = int32(12000)
+
+// Defaults for the memory regions that do not depend on the limit. The metaspace is estimated from
+// the class count in the same way as the Cloud Foundry and Paketo memory calculators.
+var memoryDefaults = map[string]int64{
+	"-Xss":                       1 * megabyte,
+	"-XX:ReservedCodeCacheSize=": 240 * megabyte,
+	"-XX:MaxDirectMemorySize=":   10 * megabyte,
+}
+
+// MemoryCalculatorReconciler checks that the JVM memory settings fit in the container memory limit.
+// If they don't, the rest of the reconcilers are skipped, so the workloads are left as they are
+// instead of being rolled out without the memory flags.
+func MemoryCalculatorReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("MemoryCalculator")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) error {
+			if micro.Spec.JVMMemory == nil {
+				meta.RemoveStatusCondition(&micro.Status.Conditions, api.MicroserviceMemoryCalculated)
+				return nil
+			}
+			// Render without the calculator, to see what it would start from
+			source := micro.DeepCopy()
+			source.Spec.JVMMemory = nil
+			template := updatePodTemplate(&corev1.PodTemplateSpec{}, resolveBindings(c, micro), source)
+			options, err := calculateMemory(findAppContainer(&template.Spec), micro.Spec.JVMMemory)
+			if err != nil {
+				meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+					Type:    api.MicroserviceMemoryCalculated,
+					Status:  metav1.ConditionFalse,
+					Reason:  "InvalidMemoryConfiguration",
+					Message: err.Error(),
+				})
+				c.Recorder.Eventf(micro, corev1.EventTypeWarning, "InvalidMemoryConfiguration", "JVM memory settings: %v", err)
+				return fmt.Errorf("cannot calculate JVM memory settings: %w", err)
+			}
+			meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+				Type:    api.MicroserviceMemoryCalculated,
+				Status:  metav1.ConditionTrue,
+				Reason:  "Calculated",
+				Message: strings.Join(options, " "),
+			})
+			return nil
+		},
+
+		Config: c,
+	}
+}
+
+// Add the calculated memory settings to JAVA_TOOL_OPTIONS. If they can't be calculated the
+// reconciler stops before any workload is updated, so the error can be ignored here.
+func addMemoryOptions(container *corev1.Container, micro *api.Microservice) {
+	if micro.Spec.JVMMemory == nil {
+		return
+	}
+	options, err := calculateMemory(container, micro.Spec.JVMMemory)
+	if err != nil {
+		return
+	}
+	appendJavaToolOptions(container, options...)
+}
+
+// Compute the JVM flags that are not already in JAVA_TOOL_OPTIONS. Flags that are there already
+// (e.g. from a binding) are respected and the rest of the memory is shared out around them.
+func calculateMemory(container *corev1.Container, spec *api.JVMMemory) ([]string, error) {
+	limit := container.Resources.Limits.Memory()
+	if limit == nil || limit.IsZero() {
+		return nil, fmt.Errorf("the app container has no memory limit")
+	}
+	threads := defaultThreadCount
+	if spec.ThreadCount != nil {
+		threads = *spec.ThreadCount
+	}
+	classes := defaultLoadedClassCount
+	if spec.LoadedClassCount != nil {
+		classes = *spec.LoadedClassCount
+	}
+	total := limit.Value()
+	if spec.HeadRoom != nil {
+		total = total * int64(100-*spec.HeadRoom) / 100
+	}
+
+	existing := strings.Fields(findJavaToolOptions(container))
+	sizes := map[string]int64{}
+	for flag, size := range memoryDefaults {
+		sizes[flag] = size
+	}
+	sizes["-XX:MaxMetaspaceSize="] = int64(classes)*5800 + 14000000
+	fixed := map[string]bool{}
+	for _, flag := range []string{"-Xmx", "-Xss", "-XX:MaxMetaspaceSize=", "-XX:ReservedCodeCacheSize=", "-XX:MaxDirectMemorySize="} {
+		if value, ok := findJavaOption(existing, flag); ok {
+			size, err := parseMemorySize(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JVM option %s%s: %v", flag, value, err)
+			}
+			sizes[flag] = size
+			fixed[flag] = true
+		}
+	}
+
+	nonHeap := sizes["-XX:MaxMetaspaceSize="] + sizes["-XX:ReservedCodeCacheSize="] + sizes["-XX:MaxDirectMemorySize="] +
+		int64(threads)*sizes["-Xss"]
+	if !fixed["-Xmx"] {
+		sizes["-Xmx"] = total - nonHeap
+		if sizes["-Xmx"] <= 0 {
+			return nil, fmt.Errorf("memory limit %s is too small: non-heap memory (%d threads, %d loaded classes) needs %dM",
+				limit.String(), threads, classes, nonHeap/megabyte)
+		}
+	} else if sizes["-Xmx"]+nonHeap > total {
+		return nil, fmt.Errorf("memory limit %s is too small: -Xmx%dM plus non-heap memory (%d threads, %d loaded classes) needs %dM",
+			limit.String(), sizes["-Xmx"]/megabyte, threads, classes, (sizes["-Xmx"]+nonHeap)/megabyte)
+	}
+
+	options := []string{}
+	for _, flag := range []string{"-XX:MaxDirectMemorySize=", "-Xmx", "-XX:MaxMetaspaceSize=", "-XX:ReservedCodeCacheSize=", "-Xss"} {
+		if !fixed[flag] {
+			options = append(options, flag+formatMemorySize(sizes[flag]))
+		}
+	}
+	return options, nil
+}
+
+func findJavaToolOptions(container *corev1.Container) string {
+	for _, env := range container.Env {
+		if env.Name == "JAVA_TOOL_OPTIONS" {
+			return env.Value
+		}
+	}
+	return ""
+}
+
+// Append options to JAVA_TOOL_OPTIONS, keeping whatever is there already
+func appendJavaToolOptions(container *corev1.Container, options ...string) {
+	values := strings.Fields(findJavaToolOptions(container))
+	for _, option := range options {
+		if !containsString(values, option) {
+			values = append(values, option)
+		}
+	}
+	container.Env = setEnvVar(container.Env, "JAVA_TOOL_OPTIONS", strings.Join(values, " "))
+}
+
+func findJavaOption(options []string, flag string) (string, bool) {
+	for _, option := range options {
+		if strings.HasPrefix(option, flag) {
+			return strings.TrimPrefix(option, flag), true
+		}
+	}
+	return "", false
+}
+
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse a JVM memory size, e.g. 512m, 1G, 1024k or a plain number of bytes
+func parseMemorySize(value string) (int64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+	unit := int64(1)
+	switch value[len(value)-1] {
+	case 'k', 'K':
+		unit = kilobyte
+	case 'm', 'M':
+		unit = megabyte
+	case 'g', 'G':
+		unit = gigabyte
+	}
+	if unit > 1 {
+		value = value[:len(value)-1]
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return size * unit, nil
+}
+
+func formatMemorySize(size int64) string {
+	if size%megabyte == 0 {
+		return fmt.Sprintf("%dM", size/megabyte)
+	}
+	return fmt.Sprintf("%dK", size/kilobyte)
+}
@@ -0,0 +1,99 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// A pod template with a memory limit on the app container
+func memoryLimitTemplate(limit string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(limit)},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestCalculateMemory(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{JVMMemory: &api.JVMMemory{}, Template: memoryLimitTemplate("1Gi")})
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	env := findEnvByName(findAppContainer(&deployment.Spec.Template.Spec).Env, "JAVA_TOOL_OPTIONS")
+	want := "-XX:MaxDirectMemorySize=10M -Xmx454935K -XX:MaxMetaspaceSize=81640K -XX:ReservedCodeCacheSize=240M -Xss1M"
+	if env.Value != want {
+		t.Errorf("JAVA_TOOL_OPTIONS = %s; want '%s'", env.Value, want)
+	}
+}
+
+func TestCalculateMemoryWithBinding(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{JVMMemory: &api.JVMMemory{}, Template: memoryLimitTemplate("1Gi")})
+	binding := defaultBinding("jvm", micro)
+	binding.Spec.Env = []api.EnvVar{{Name: "JAVA_TOOL_OPTIONS", Value: "-Xss512k -Dfoo=bar"}}
+	deployment := createDeployment([]api.ServiceBinding{binding}, &micro)
+	env := findEnvByName(findAppContainer(&deployment.Spec.Template.Spec).Env, "JAVA_TOOL_OPTIONS")
+	if !strings.HasPrefix(env.Value, "-Xss512k -Dfoo=bar -XX:MaxDirectMemorySize=10M -Xmx") {
+		t.Errorf("JAVA_TOOL_OPTIONS = %s; want binding options first", env.Value)
+	}
+	if strings.Contains(env.Value, "-Xss1M") {
+		t.Errorf("JAVA_TOOL_OPTIONS = %s; want no '-Xss1M'", env.Value)
+	}
+}
+
+func TestCalculateMemoryTooSmall(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{JVMMemory: &api.JVMMemory{}, Template: memoryLimitTemplate("256Mi")})
+	container := findAppContainer(&micro.Spec.Template.Spec)
+	if _, err := calculateMemory(container, micro.Spec.JVMMemory); err == nil {
+		t.Errorf("calculateMemory() = nil; want error")
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	if env := findEnvByName(findAppContainer(&deployment.Spec.Template.Spec).Env, "JAVA_TOOL_OPTIONS"); env.Value != "" {
+		t.Errorf("JAVA_TOOL_OPTIONS = %s; want ''", env.Value)
+	}
+	threads := int32(20)
+	micro.Spec.JVMMemory.ThreadCount = &threads
+	container.Env = []corev1.EnvVar{{Name: "JAVA_TOOL_OPTIONS", Value: "-Xmx200m"}}
+	if _, err := calculateMemory(container, micro.Spec.JVMMemory); err == nil {
+		t.Errorf("calculateMemory() = nil; want error")
+	}
+	container.Resources.Limits = nil
+	if _, err := calculateMemory(container, micro.Spec.JVMMemory); err == nil {
+		t.Errorf("calculateMemory() = nil; want error")
+	}
+}
+
+func TestParseMemorySize(t *testing.T) {
+	for value, want := range map[string]int64{"512m": 512 * megabyte, "1G": gigabyte, "1024k": megabyte, "100": 100} {
+		size, err := parseMemorySize(value)
+		if err != nil || size != want {
+			t.Errorf("parseMemorySize(%s) = %d; want %d", value, size, want)
+		}
+	}
+	if _, err := parseMemorySize("lots"); err == nil {
+		t.Errorf("parseMemorySize(lots) = nil; want error")
+	}
+}
@@ -186,7 +186,7 @@ func TestMergeContainersWithProbes(t *testing.T) {
 		Image: "springguides/demo",
 		LivenessProbe: &corev1.Probe{
 			InitialDelaySeconds: 30,
-			Handler: corev1.Handler{
+			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{Path: "/info"},
 			},
 		},
@@ -200,11 +200,11 @@ func TestMergeContainersWithProbes(t *testing.T) {
 		t.Errorf("Container.LivenessProbe.InitialDelaySeconds = %d; want 30", target.LivenessProbe.InitialDelaySeconds)
 		t.FailNow()
 	}
-	if target.LivenessProbe.Handler.HTTPGet == nil {
+	if target.LivenessProbe.ProbeHandler.HTTPGet == nil {
 		t.Errorf("Container.LivenessProbe = %s; want not 'nil'", target.LivenessProbe)
 		t.FailNow()
 	}
-	if target.LivenessProbe.Handler.HTTPGet.Path != "/info" {
-		t.Errorf("Container.LivenessProbe.Handler.HTTPGet.Path = %s; want 'nil'", target.LivenessProbe.Handler.HTTPGet.Path)
+	if target.LivenessProbe.ProbeHandler.HTTPGet.Path != "/info" {
+		t.Errorf("Container.LivenessProbe.ProbeHandler.HTTPGet.Path = %s; want 'nil'", target.LivenessProbe.ProbeHandler.HTTPGet.Path)
 	}
 }
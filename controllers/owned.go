@@ -0,0 +1,123 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+// Some of the objects that the operator creates (the canary and colour Deployments, the preview and
+// debug Services, and the properties ConfigMap) are owned by the Microservice, but not controlled by
+// it. They are garbage collected with the Microservice, but a ChildReconciler for the same type
+// (e.g. the main Deployment or Service) does not see them as extra children and delete them. Since no
+// ChildReconciler manages them, an ownedObject does the create, update and delete instead.
+type ownedObject struct {
+	Kind string
+	// True if the current object is up to date with the desired one
+	SemanticEquals func(current, desired client.Object) bool
+	// Copy the desired fields on to (a copy of) the current object before it is updated
+	MergeBeforeUpdate func(current, desired client.Object)
+}
+
+// Create the desired object, or update the current one (nil if there is none) to match it. Returns the
+// object as it is now, or nil if there is an object with the same name that is not ours.
+func (o ownedObject) apply(ctx context.Context, c reconcilers.Config, micro *api.Microservice, current, desired client.Object) (client.Object, error) {
+	if current != nil && !isOwnedBy(current, micro) {
+		c.Recorder.Eventf(micro, corev1.EventTypeWarning, "NotOwned",
+			"%s %q already exists and is not owned by the Microservice", o.Kind, current.GetName())
+		return nil, nil
+	}
+	desired.SetOwnerReferences([]metav1.OwnerReference{ownerReference(micro)})
+	if current == nil {
+		c.Log.Info("creating", "kind", o.Kind, "name", desired.GetName())
+		if err := c.Create(ctx, desired); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				// The cache has not caught up with the object created on the last turn
+				return desired, nil
+			}
+			c.Recorder.Eventf(micro, corev1.EventTypeWarning, "CreationFailed",
+				"Failed to create %s %q: %v", o.Kind, desired.GetName(), err)
+			return nil, err
+		}
+		c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Created", "Created %s %q", o.Kind, desired.GetName())
+		return desired, nil
+	}
+	if o.SemanticEquals(current, desired) {
+		return current, nil
+	}
+	updated := current.DeepCopyObject().(client.Object)
+	o.MergeBeforeUpdate(updated, desired)
+	c.Log.Info("updating", "kind", o.Kind, "name", updated.GetName())
+	if err := c.Update(ctx, updated); err != nil {
+		c.Recorder.Eventf(micro, corev1.EventTypeWarning, "UpdateFailed",
+			"Failed to update %s %q: %v", o.Kind, updated.GetName(), err)
+		return nil, err
+	}
+	c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Updated", "Updated %s %q", o.Kind, updated.GetName())
+	return updated, nil
+}
+
+// Delete the current object, if there is one and it is ours
+func (o ownedObject) delete(ctx context.Context, c reconcilers.Config, micro *api.Microservice, current client.Object) error {
+	if current == nil || !isOwnedBy(current, micro) {
+		return nil
+	}
+	c.Log.Info("deleting", "kind", o.Kind, "name", current.GetName())
+	if err := c.Delete(ctx, current); err != nil && !apierrors.IsNotFound(err) {
+		c.Recorder.Eventf(micro, corev1.EventTypeWarning, "DeleteFailed",
+			"Failed to delete %s %q: %v", o.Kind, current.GetName(), err)
+		return err
+	}
+	c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Deleted", "Deleted %s %q", o.Kind, current.GetName())
+	return nil
+}
+
+// Look up an object by name, returning nil if it does not exist
+func getOwned(ctx context.Context, c reconcilers.Config, key client.ObjectKey, object client.Object) (client.Object, error) {
+	if err := c.Get(ctx, key, object); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+// An owner reference that is not a controller, so the object is garbage collected with the
+// Microservice, but not picked up by a ChildReconciler
+func ownerReference(micro *api.Microservice) metav1.OwnerReference {
+	controller := false
+	reference := *metav1.NewControllerRef(micro, api.GroupVersion.WithKind("Microservice"))
+	reference.Controller = &controller
+	return reference
+}
+
+func isOwnedBy(object metav1.Object, micro *api.Microservice) bool {
+	for _, reference := range object.GetOwnerReferences() {
+		if reference.UID == micro.UID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOwnerReference(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "demo",
+			UID:  "1234",
+		},
+	}
+	reference := ownerReference(&micro)
+	if *reference.Controller {
+		t.Errorf("OwnerReference.Controller = true; want false")
+	}
+	if reference.Kind != "Microservice" {
+		t.Errorf("OwnerReference.Kind = %s; want 'Microservice'", reference.Kind)
+	}
+	object := metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{reference}}
+	if !isOwnedBy(&object, &micro) {
+		t.Errorf("isOwnedBy() = false; want true")
+	}
+}
+
+func TestOwnedService(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "demo",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "springguides/demo",
+			Debug: true,
+		},
+	}
+	current := createDebugService(&micro)
+	current.Annotations["cloud.example.com/id"] = "123"
+	current.Spec.ClusterIP = "10.0.0.1"
+	if !ownedService.SemanticEquals(current, createDebugService(&micro)) {
+		t.Errorf("SemanticEquals() = false; want true")
+	}
+	micro.Name = "other"
+	desired := createDebugService(&micro)
+	if ownedService.SemanticEquals(current, desired) {
+		t.Errorf("SemanticEquals() = true; want false")
+	}
+	updated := current.DeepCopy()
+	ownedService.MergeBeforeUpdate(updated, desired)
+	if updated.Spec.Selector["app"] != "other" {
+		t.Errorf("Service.Spec.Selector = %s; want 'app=other'", updated.Spec.Selector)
+	}
+	if updated.Spec.ClusterIP != "10.0.0.1" || updated.Annotations["cloud.example.com/id"] != "123" {
+		t.Errorf("Service = %v; want the cluster IP and annotations kept", updated)
+	}
+	if updated.Annotations["spring.io/spec-hash"] != desired.Annotations["spring.io/spec-hash"] {
+		t.Errorf("Annotations = %s; want the new 'spring.io/spec-hash'", updated.Annotations)
+	}
+	if current.Spec.Selector["app"] != "demo" {
+		t.Errorf("Service.Spec.Selector = %s; want the current Service unchanged", current.Spec.Selector)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	"github.com/vmware-labs/reconciler-runtime/tracker"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+// PodReferencesReconciler checks that the image pull secrets and service account exist
+func PodReferencesReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("PodReferences")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) error {
+			if len(micro.Spec.ImagePullSecrets) == 0 && micro.Spec.ServiceAccountName == "" {
+				meta.RemoveStatusCondition(&micro.Status.Conditions, api.MicroservicePodReferencesResolved)
+				return nil
+			}
+			parent := types.NamespacedName{Namespace: micro.Namespace, Name: micro.Name}
+			missing := []string{}
+			for _, secret := range micro.Spec.ImagePullSecrets {
+				key := types.NamespacedName{Namespace: micro.Namespace, Name: secret.Name}
+				c.Tracker.Track(tracker.NewKey(corev1.SchemeGroupVersion.WithKind("Secret"), key), parent)
+				found, err := exists(ctx, c, key, &corev1.Secret{})
+				if err != nil {
+					return err
+				}
+				if !found {
+					missing = append(missing, fmt.Sprintf("secret %q not found", secret.Name))
+				}
+			}
+			if name := micro.Spec.ServiceAccountName; name != "" {
+				key := types.NamespacedName{Namespace: micro.Namespace, Name: name}
+				c.Tracker.Track(tracker.NewKey(corev1.SchemeGroupVersion.WithKind("ServiceAccount"), key), parent)
+				found, err := exists(ctx, c, key, &corev1.ServiceAccount{})
+				if err != nil {
+					return err
+				}
+				if !found {
+					missing = append(missing, fmt.Sprintf("service account %q not found", name))
+				}
+			}
+			if len(missing) > 0 {
+				meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+					Type:    api.MicroservicePodReferencesResolved,
+					Status:  metav1.ConditionFalse,
+					Reason:  "MissingReferences",
+					Message: strings.Join(missing, "; "),
+				})
+				return nil
+			}
+			meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+				Type:    api.MicroservicePodReferencesResolved,
+				Status:  metav1.ConditionTrue,
+				Reason:  "Resolved",
+				Message: "",
+			})
+			return nil
+		},
+
+		Config: c,
+
+		Setup: func(ctx context.Context, mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
+			bldr.Watches(&source.Kind{Type: &corev1.Secret{}}, reconcilers.EnqueueTracked(&corev1.Secret{}, c.Tracker, c.Scheme()))
+			bldr.Watches(&source.Kind{Type: &corev1.ServiceAccount{}}, reconcilers.EnqueueTracked(&corev1.ServiceAccount{}, c.Tracker, c.Scheme()))
+			return nil
+		},
+	}
+}
+
+func exists(ctx context.Context, c reconcilers.Config, key types.NamespacedName, object client.Object) (bool, error) {
+	if err := c.Get(ctx, client.ObjectKey(key), object); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Apply the pod settings from the top level of the spec, which win over the template and bindings
+func addPodSettings(template *corev1.PodTemplateSpec, container *corev1.Container, micro *api.Microservice) {
+	if name := serviceAccountName(micro); name != "" {
+		template.Spec.ServiceAccountName = name
+	}
+	if micro.Spec.ImagePullPolicy != "" {
+		container.ImagePullPolicy = micro.Spec.ImagePullPolicy
+	}
+	if len(micro.Spec.ImagePullSecrets) > 0 {
+		secrets := append([]corev1.LocalObjectReference{}, micro.Spec.ImagePullSecrets...)
+		for _, secret := range template.Spec.ImagePullSecrets {
+			if !containsSecret(secrets, secret.Name) {
+				secrets = append(secrets, secret)
+			}
+		}
+		template.Spec.ImagePullSecrets = secrets
+	}
+}
+
+// The service account from the spec, or the generated one for Spring Cloud Kubernetes
+func serviceAccountName(micro *api.Microservice) string {
+	if micro.Spec.ServiceAccountName == "" && micro.Spec.SpringCloudKubernetes != nil {
+		return micro.Name
+	}
+	return micro.Spec.ServiceAccountName
+}
+
+func containsSecret(secrets []corev1.LocalObjectReference, name string) bool {
+	for _, secret := range secrets {
+		if secret.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateDeploymentPodSettings(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:              "springguides/demo",
+			ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "registry"}},
+			ImagePullPolicy:    corev1.PullAlways,
+			ServiceAccountName: "demo",
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					ServiceAccountName: "template",
+					Containers:         []corev1.Container{{Name: "app", ImagePullPolicy: corev1.PullNever}},
+				},
+			},
+		},
+	}
+	binding := defaultBinding("registry", micro)
+	binding.Spec.Template.Spec.ServiceAccountName = "binding"
+	binding.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "other"}, {Name: "registry"}}
+	deployment := createDeployment([]api.ServiceBinding{binding}, &micro)
+	spec := deployment.Spec.Template.Spec
+	if spec.ServiceAccountName != "demo" {
+		t.Errorf("ServiceAccountName = %s; want 'demo'", spec.ServiceAccountName)
+	}
+	if len(spec.ImagePullSecrets) != 2 || spec.ImagePullSecrets[0].Name != "registry" || spec.ImagePullSecrets[1].Name != "other" {
+		t.Errorf("ImagePullSecrets = %v; want 'registry', 'other'", spec.ImagePullSecrets)
+	}
+	if findAppContainer(&spec).ImagePullPolicy != corev1.PullAlways {
+		t.Errorf("ImagePullPolicy = %s; want 'Always'", findAppContainer(&spec).ImagePullPolicy)
+	}
+}
+
+func TestCreateDeploymentPodSettingsDefault(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "springguides/demo",
+		},
+	}
+	binding := defaultBinding("registry", micro)
+	binding.Spec.Template.Spec.ServiceAccountName = "binding"
+	deployment := createDeployment([]api.ServiceBinding{binding}, &micro)
+	if deployment.Spec.Template.Spec.ServiceAccountName != "binding" {
+		t.Errorf("ServiceAccountName = %s; want 'binding'", deployment.Spec.Template.Spec.ServiceAccountName)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var defaultActuatorPath = "/actuator"
+var defaultProbePeriodSeconds = int32(10)
+var defaultStartupFailureThreshold = int32(30)
+
+// Point the probes at the liveness and readiness health groups on the management port, unless the
+// template of the Microservice already has its own
+func addProbes(container *corev1.Container, micro *api.Microservice) {
+	options := micro.Spec.Probes
+	if options == nil {
+		return
+	}
+	base := actuatorBasePath(micro)
+	period := defaultProbePeriodSeconds
+	if options.PeriodSeconds != nil {
+		period = *options.PeriodSeconds
+	}
+	startup := defaultStartupFailureThreshold
+	if options.StartupFailureThreshold != nil {
+		startup = *options.StartupFailureThreshold
+	}
+	port := managementPort(micro)
+	user := userAppContainer(micro)
+	if user == nil || user.LivenessProbe == nil {
+		container.LivenessProbe = healthProbe(base+"/health/liveness", port, period, 3)
+	}
+	if user == nil || user.ReadinessProbe == nil {
+		container.ReadinessProbe = healthProbe(base+"/health/readiness", port, period, 3)
+	}
+	if user == nil || user.StartupProbe == nil {
+		container.StartupProbe = healthProbe(base+"/health/liveness", port, period, startup)
+	}
+	container.Env = setEnvVar(container.Env, "MANAGEMENT_ENDPOINT_HEALTH_PROBES_ENABLED", "true")
+}
+
+// The base path of the actuator endpoints, from the probe options if there are any
+func actuatorBasePath(micro *api.Microservice) string {
+	if micro.Spec.Probes != nil {
+		if base := strings.TrimSuffix(micro.Spec.Probes.BasePath, "/"); base != "" {
+			return base
+		}
+	}
+	return defaultActuatorPath
+}
+
+func healthProbe(path string, port int32, period int32, failures int32) *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: path,
+				Port: intstr.FromInt(int(port)),
+			},
+		},
+		PeriodSeconds:    period,
+		FailureThreshold: failures,
+	}
+}
+
+// The app container in the template of the Microservice, if there is one
+func userAppContainer(micro *api.Microservice) *corev1.Container {
+	containers := micro.Spec.Template.Spec.Containers
+	if len(containers) == 1 {
+		return &containers[0]
+	}
+	for index, container := range containers {
+		if container.Name == "app" {
+			return &containers[index]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestCreateDeploymentProbes(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:          "springguides/demo",
+			ManagementPort: 8081,
+			Probes:         &api.Probes{},
+		},
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	container := findAppContainer(&deployment.Spec.Template.Spec)
+	if container.LivenessProbe.HTTPGet.Path != "/actuator/health/liveness" {
+		t.Errorf("LivenessProbe.Path = %s; want '/actuator/health/liveness'", container.LivenessProbe.HTTPGet.Path)
+	}
+	if container.LivenessProbe.HTTPGet.Port.IntValue() != 8081 {
+		t.Errorf("LivenessProbe.Port = %s; want '8081'", container.LivenessProbe.HTTPGet.Port.String())
+	}
+	if container.ReadinessProbe.HTTPGet.Path != "/actuator/health/readiness" {
+		t.Errorf("ReadinessProbe.Path = %s; want '/actuator/health/readiness'", container.ReadinessProbe.HTTPGet.Path)
+	}
+	if container.StartupProbe.FailureThreshold != 30 {
+		t.Errorf("StartupProbe.FailureThreshold = %d; want 30", container.StartupProbe.FailureThreshold)
+	}
+	if findEnvByName(container.Env, "MANAGEMENT_ENDPOINT_HEALTH_PROBES_ENABLED").Value != "true" {
+		t.Errorf("MANAGEMENT_ENDPOINT_HEALTH_PROBES_ENABLED = %s; want 'true'", findEnvByName(container.Env, "MANAGEMENT_ENDPOINT_HEALTH_PROBES_ENABLED").Value)
+	}
+}
+
+func TestCreateDeploymentProbesUserWins(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:  "springguides/demo",
+			Probes: &api.Probes{BasePath: "/manage/"},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "app",
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									HTTPGet: &corev1.HTTPGetAction{Path: "/ready", Port: intstr.FromInt(8080)},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	binding := defaultBinding("actuators", micro)
+	findAppContainer(&binding.Spec.Template.Spec).LivenessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{Path: "/actuator/info", Port: intstr.FromInt(8080)},
+		},
+	}
+	deployment := createDeployment([]api.ServiceBinding{binding}, &micro)
+	container := findAppContainer(&deployment.Spec.Template.Spec)
+	if container.ReadinessProbe.HTTPGet.Path != "/ready" {
+		t.Errorf("ReadinessProbe.Path = %s; want '/ready'", container.ReadinessProbe.HTTPGet.Path)
+	}
+	if container.LivenessProbe.HTTPGet.Path != "/manage/health/liveness" {
+		t.Errorf("LivenessProbe.Path = %s; want '/manage/health/liveness'", container.LivenessProbe.HTTPGet.Path)
+	}
+}
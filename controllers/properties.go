@@ -0,0 +1,216 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var propertiesVolume = "spring-properties"
+var propertiesPath = "/etc/config/spring/"
+var propertiesHashAnnotation = "spring.io/properties-hash"
+
+var ownedConfigMap = ownedObject{
+	Kind: "ConfigMap",
+	SemanticEquals: func(current, desired client.Object) bool {
+		c, d := current.(*corev1.ConfigMap), desired.(*corev1.ConfigMap)
+		return equality.Semantic.DeepEqual(c.Data, d.Data) && equality.Semantic.DeepEqual(c.Labels, d.Labels)
+	},
+	MergeBeforeUpdate: func(current, desired client.Object) {
+		c, d := current.(*corev1.ConfigMap), desired.(*corev1.ConfigMap)
+		c.Labels = d.Labels
+		c.Data = d.Data
+	},
+}
+
+// PropertiesReconciler renders the inline properties into a ConfigMap
+func PropertiesReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("Properties")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) error {
+			key := client.ObjectKey{Namespace: micro.Namespace, Name: propertiesName(micro)}
+			current, err := getOwned(ctx, c, key, &corev1.ConfigMap{})
+			if err != nil {
+				return err
+			}
+			desired := createPropertiesConfigMap(micro)
+			if desired == nil {
+				return ownedConfigMap.delete(ctx, c, micro, current)
+			}
+			_, err = ownedConfigMap.apply(ctx, c, micro, current, desired)
+			return err
+		},
+
+		Config: c,
+
+		Setup: func(ctx context.Context, mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
+			bldr.Watches(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForOwner{
+				OwnerType:    &api.Microservice{},
+				IsController: false,
+			})
+			return nil
+		},
+	}
+}
+
+func propertiesName(micro *api.Microservice) string {
+	return fmt.Sprintf("%s-properties", micro.Name)
+}
+
+func createPropertiesConfigMap(micro *api.Microservice) *corev1.ConfigMap {
+	data := renderProperties(micro)
+	if data == nil {
+		return nil
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    map[string]string{"app": micro.Name},
+			Name:      propertiesName(micro),
+			Namespace: micro.Namespace,
+		},
+		Data: data,
+	}
+}
+
+// The files that go in the properties ConfigMap, or nil if there are none
+func renderProperties(micro *api.Microservice) map[string]string {
+	if len(micro.Spec.Properties) == 0 && micro.Spec.ApplicationYAML == "" {
+		return nil
+	}
+	data := map[string]string{}
+	if len(micro.Spec.Properties) > 0 {
+		lines := []string{}
+		for _, key := range sortedKeys(micro.Spec.Properties) {
+			lines = append(lines, escapeProperty(key, true)+"="+escapeProperty(micro.Spec.Properties[key], false))
+		}
+		data["application.properties"] = strings.Join(lines, "\n") + "\n"
+	}
+	if micro.Spec.ApplicationYAML != "" {
+		data["application.yaml"] = micro.Spec.ApplicationYAML
+	}
+	return data
+}
+
+// Escape a key or value for a Java properties file. The file is read as ISO-8859-1, so anything
+// that is not printable ASCII goes in as a unicode escape.
+func escapeProperty(value string, key bool) string {
+	var result strings.Builder
+	for index, char := range value {
+		switch char {
+		case '\\':
+			result.WriteString("\\\\")
+		case '\n':
+			result.WriteString("\\n")
+		case '\r':
+			result.WriteString("\\r")
+		case '\t':
+			result.WriteString("\\t")
+		case '=', ':', '#', '!', ' ':
+			if key || index == 0 {
+				result.WriteRune('\\')
+			}
+			result.WriteRune(char)
+		default:
+			if char < 0x20 || char > 0x7e {
+				writeUnicodeEscape(&result, char)
+			} else {
+				result.WriteRune(char)
+			}
+		}
+	}
+	return result.String()
+}
+
+func writeUnicodeEscape(result *strings.Builder, char rune) {
+	if r1, r2 := utf16.EncodeRune(char); r1 != unicode.ReplacementChar {
+		fmt.Fprintf(result, "\\u%04x\\u%04x", r1, r2)
+		return
+	}
+	fmt.Fprintf(result, "\\u%04x", char)
+}
+
+// Mount the properties ConfigMap in the app container, and add a hash of its contents to the pod
+// template, so that changes are rolled out
+func addProperties(template *corev1.PodTemplateSpec, container *corev1.Container, micro *api.Microservice) {
+	data := renderProperties(micro)
+	if data == nil {
+		return
+	}
+	template.Spec.Volumes = setVolume(template.Spec.Volumes, corev1.Volume{
+		Name: propertiesVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: propertiesName(micro)},
+			},
+		},
+	})
+	container.VolumeMounts = setVolumeMount(container.VolumeMounts, corev1.VolumeMount{
+		Name:      propertiesVolume,
+		MountPath: propertiesPath,
+		ReadOnly:  true,
+	})
+	location := "file:" + propertiesPath
+	locations := []string{}
+	for _, env := range container.Env {
+		if env.Name == "SPRING_CONFIG_ADDITIONAL_LOCATION" && env.Value != "" {
+			locations = strings.Split(env.Value, ",")
+		}
+	}
+	if !containsString(locations, location) {
+		locations = append(locations, location)
+	}
+	container.Env = setEnvVar(container.Env, "SPRING_CONFIG_ADDITIONAL_LOCATION", strings.Join(locations, ","))
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[propertiesHashAnnotation] = computeHash(data)
+}
+
+func setVolume(volumes []corev1.Volume, volume corev1.Volume) []corev1.Volume {
+	for index, existing := range volumes {
+		if existing.Name == volume.Name {
+			volumes[index] = volume
+			return volumes
+		}
+	}
+	return append(volumes, volume)
+}
+
+func setVolumeMount(mounts []corev1.VolumeMount, mount corev1.VolumeMount) []corev1.VolumeMount {
+	for index, existing := range mounts {
+		if existing.Name == mount.Name {
+			mounts[index] = mount
+			return mounts
+		}
+	}
+	return append(mounts, mount)
+}
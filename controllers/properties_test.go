@@ -0,0 +1,104 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+func TestCreatePropertiesConfigMap(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{
+		Properties: map[string]string{
+			"spring.application.name": "demo",
+			"logging.level.root":      "DEBUG",
+			"app.message":             "Hello: World\nAgain",
+		},
+		ApplicationYAML: "app:\n  name: demo\n---\nspring:\n  profiles: cloud\n",
+	})
+	config := createPropertiesConfigMap(&micro)
+	if config.Name != "demo-properties" {
+		t.Errorf("ConfigMap.Name = %s; want 'demo-properties'", config.Name)
+	}
+	want := "app.message=Hello: World\\nAgain\nlogging.level.root=DEBUG\nspring.application.name=demo\n"
+	if config.Data["application.properties"] != want {
+		t.Errorf("ConfigMap.Data = %s; want '%s'", config.Data["application.properties"], want)
+	}
+	if config.Data["application.yaml"] != micro.Spec.ApplicationYAML {
+		t.Errorf("ConfigMap.Data = %s; want '%s'", config.Data["application.yaml"], micro.Spec.ApplicationYAML)
+	}
+	micro.Spec.Properties = nil
+	micro.Spec.ApplicationYAML = ""
+	if createPropertiesConfigMap(&micro) != nil {
+		t.Errorf("ConfigMap = not nil; want nil")
+	}
+}
+
+func TestEscapeProperty(t *testing.T) {
+	if escapeProperty("a key=b", true) != "a\\ key\\=b" {
+		t.Errorf("escapeProperty() = %s; want 'a\\ key\\=b'", escapeProperty("a key=b", true))
+	}
+	if escapeProperty(" a=b", false) != "\\ a=b" {
+		t.Errorf("escapeProperty() = %s; want '\\ a=b'", escapeProperty(" a=b", false))
+	}
+	if escapeProperty("café €", false) != "caf\\u00e9 \\u20ac" {
+		t.Errorf("escapeProperty() = %s; want 'caf\\u00e9 \\u20ac'", escapeProperty("café €", false))
+	}
+	if escapeProperty("😀", false) != "\\ud83d\\ude00" {
+		t.Errorf("escapeProperty() = %s; want '\\ud83d\\ude00'", escapeProperty("😀", false))
+	}
+}
+
+func TestCreateDeploymentProperties(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{
+		Properties:      map[string]string{"logging.level.root": "DEBUG"},
+		ApplicationYAML: "app:\n  name: demo\n",
+	})
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	spec := deployment.Spec.Template.Spec
+	if len(spec.Volumes) != 1 || spec.Volumes[0].ConfigMap.Name != "demo-properties" {
+		t.Errorf("Volumes = %v; want 'demo-properties'", spec.Volumes)
+	}
+	container := findAppContainer(&spec)
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].MountPath != "/etc/config/spring/" {
+		t.Errorf("VolumeMounts = %v; want '/etc/config/spring/'", container.VolumeMounts)
+	}
+	env := findEnvByName(container.Env, "SPRING_CONFIG_ADDITIONAL_LOCATION")
+	if env.Value != "file:/etc/config/spring/" {
+		t.Errorf("SPRING_CONFIG_ADDITIONAL_LOCATION = %s; want 'file:/etc/config/spring/'", env.Value)
+	}
+	hash := deployment.Spec.Template.Annotations["spring.io/properties-hash"]
+	if hash == "" {
+		t.Errorf("Annotations = %s; want 'spring.io/properties-hash'", deployment.Spec.Template.Annotations)
+	}
+	micro.Spec.Properties["logging.level.root"] = "INFO"
+	deployment = createDeployment([]api.ServiceBinding{}, &micro)
+	if deployment.Spec.Template.Annotations["spring.io/properties-hash"] == hash {
+		t.Errorf("Hash = %s; want it to change", hash)
+	}
+}
+
+func TestCreateDeploymentPropertiesAdditionalLocation(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{Properties: map[string]string{"logging.level.root": "DEBUG"}})
+	binding := defaultBinding("config", micro)
+	binding.Spec.Env = []api.EnvVar{{Name: "SPRING_CONFIG_ADDITIONAL_LOCATION", Value: "file:/etc/other/"}}
+	deployment := createDeployment([]api.ServiceBinding{binding}, &micro)
+	env := findEnvByName(findAppContainer(&deployment.Spec.Template.Spec).Env, "SPRING_CONFIG_ADDITIONAL_LOCATION")
+	if env.Value != "file:/etc/other/,file:/etc/config/spring/" {
+		t.Errorf("SPRING_CONFIG_ADDITIONAL_LOCATION = %s; want 'file:/etc/other/,file:/etc/config/spring/'", env.Value)
+	}
+}
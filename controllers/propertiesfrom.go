@@ -0,0 +1,178 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	"github.com/vmware-labs/reconciler-runtime/tracker"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+// PropertiesFromReconciler checks that the Secret and ConfigMap keys in propertiesFrom exist
+func PropertiesFromReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("PropertiesFrom")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) error {
+			missing := []string{}
+			for _, property := range sortedKeys(micro.Spec.PropertiesFrom) {
+				problem, err := checkPropertySource(ctx, c, micro, micro.Spec.PropertiesFrom[property])
+				if err != nil {
+					return err
+				}
+				if problem != "" {
+					missing = append(missing, fmt.Sprintf("%s: %s", property, problem))
+				}
+			}
+			if len(micro.Spec.PropertiesFrom) == 0 {
+				micro.Status.MissingProperties = nil
+				meta.RemoveStatusCondition(&micro.Status.Conditions, api.MicroservicePropertiesResolved)
+				return nil
+			}
+			if len(missing) > 0 {
+				micro.Status.MissingProperties = missing
+				meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+					Type:    api.MicroservicePropertiesResolved,
+					Status:  metav1.ConditionFalse,
+					Reason:  "MissingReferences",
+					Message: strings.Join(missing, "; "),
+				})
+				return nil
+			}
+			micro.Status.MissingProperties = nil
+			meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+				Type:    api.MicroservicePropertiesResolved,
+				Status:  metav1.ConditionTrue,
+				Reason:  "Resolved",
+				Message: "",
+			})
+			return nil
+		},
+
+		Config: c,
+
+		Setup: func(ctx context.Context, mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
+			bldr.Watches(&source.Kind{Type: &corev1.Secret{}}, reconcilers.EnqueueTracked(&corev1.Secret{}, c.Tracker, c.Scheme()))
+			bldr.Watches(&source.Kind{Type: &corev1.ConfigMap{}}, reconcilers.EnqueueTracked(&corev1.ConfigMap{}, c.Tracker, c.Scheme()))
+			return nil
+		},
+	}
+}
+
+// Look up the Secret or ConfigMap for a property and describe the problem if the key is missing
+func checkPropertySource(ctx context.Context, c reconcilers.Config, micro *api.Microservice, property api.PropertyValueSource) (string, error) {
+	parent := types.NamespacedName{Namespace: micro.Namespace, Name: micro.Name}
+	if ref := property.SecretKeyRef; ref != nil {
+		key := types.NamespacedName{Namespace: micro.Namespace, Name: ref.Name}
+		c.Tracker.Track(tracker.NewKey(corev1.SchemeGroupVersion.WithKind("Secret"), key), parent)
+		var secret corev1.Secret
+		if err := c.Get(ctx, client.ObjectKey(key), &secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return "", err
+			}
+			if isOptional(ref.Optional) {
+				return "", nil
+			}
+			return fmt.Sprintf("secret %q not found", ref.Name), nil
+		}
+		if _, ok := secret.Data[ref.Key]; !ok && !isOptional(ref.Optional) {
+			return fmt.Sprintf("secret %q has no key %q", ref.Name, ref.Key), nil
+		}
+		return "", nil
+	}
+	if ref := property.ConfigMapKeyRef; ref != nil {
+		key := types.NamespacedName{Namespace: micro.Namespace, Name: ref.Name}
+		c.Tracker.Track(tracker.NewKey(corev1.SchemeGroupVersion.WithKind("ConfigMap"), key), parent)
+		var config corev1.ConfigMap
+		if err := c.Get(ctx, client.ObjectKey(key), &config); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return "", err
+			}
+			if isOptional(ref.Optional) {
+				return "", nil
+			}
+			return fmt.Sprintf("configmap %q not found", ref.Name), nil
+		}
+		_, ok := config.Data[ref.Key]
+		if _, binary := config.BinaryData[ref.Key]; !ok && !binary && !isOptional(ref.Optional) {
+			return fmt.Sprintf("configmap %q has no key %q", ref.Name, ref.Key), nil
+		}
+		return "", nil
+	}
+	return "no secretKeyRef or configMapKeyRef", nil
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+// Add an env var for each of the propertiesFrom, using its relaxed binding name
+func addPropertiesFrom(container *corev1.Container, micro *api.Microservice) {
+	for _, property := range sortedKeys(micro.Spec.PropertiesFrom) {
+		source := micro.Spec.PropertiesFrom[property]
+		env := corev1.EnvVar{
+			Name: relaxedName(property),
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef:    source.SecretKeyRef,
+				ConfigMapKeyRef: source.ConfigMapKeyRef,
+			},
+		}
+		if env.ValueFrom.SecretKeyRef == nil && env.ValueFrom.ConfigMapKeyRef == nil {
+			continue
+		}
+		if env.ValueFrom.SecretKeyRef != nil {
+			// Only one source is allowed in an env var
+			env.ValueFrom.ConfigMapKeyRef = nil
+		}
+		container.Env = setEnvVarSource(container.Env, env)
+	}
+}
+
+func setEnvVarSource(values []corev1.EnvVar, env corev1.EnvVar) []corev1.EnvVar {
+	for index, existing := range values {
+		if existing.Name == env.Name {
+			values[index] = env
+			return values
+		}
+	}
+	return append(values, env)
+}
+
+var indexPattern = regexp.MustCompile(`\[(\d+)\]`)
+
+// The env var name that Spring Boot binds to a property, e.g. spring.datasource.password is
+// SPRING_DATASOURCE_PASSWORD and my.list[0].first-name is MY_LIST_0_FIRSTNAME
+func relaxedName(property string) string {
+	name := indexPattern.ReplaceAllString(property, "_${1}_")
+	name = strings.ReplaceAll(name, ".", "_")
+	name = strings.ReplaceAll(name, "-", "")
+	name = strings.ReplaceAll(name, "__", "_")
+	return strings.Trim(strings.ToUpper(name), "_")
+}
@@ -0,0 +1,72 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRelaxedName(t *testing.T) {
+	for property, want := range map[string]string{
+		"spring.datasource.password": "SPRING_DATASOURCE_PASSWORD",
+		"my.list[0].first-name":      "MY_LIST_0_FIRSTNAME",
+		"server.port":                "SERVER_PORT",
+	} {
+		if relaxedName(property) != want {
+			t.Errorf("relaxedName(%s) = %s; want '%s'", property, relaxedName(property), want)
+		}
+	}
+}
+
+func TestCreateDeploymentPropertiesFrom(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "springguides/demo",
+			PropertiesFrom: map[string]api.PropertyValueSource{
+				"spring.datasource.password": {
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "db"},
+						Key:                  "password",
+					},
+				},
+				"spring.datasource.url": {
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "db"},
+						Key:                  "url",
+					},
+				},
+			},
+		},
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	container := findAppContainer(&deployment.Spec.Template.Spec)
+	env := findEnvByName(container.Env, "SPRING_DATASOURCE_PASSWORD")
+	if env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil || env.ValueFrom.SecretKeyRef.Key != "password" {
+		t.Errorf("SPRING_DATASOURCE_PASSWORD = %v; want secret 'db' key 'password'", env)
+	}
+	env = findEnvByName(container.Env, "SPRING_DATASOURCE_URL")
+	if env.ValueFrom == nil || env.ValueFrom.ConfigMapKeyRef == nil || env.ValueFrom.ConfigMapKeyRef.Name != "db" {
+		t.Errorf("SPRING_DATASOURCE_URL = %v; want configmap 'db' key 'url'", env)
+	}
+}
@@ -0,0 +1,238 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var (
+	// The kubelet updates files mounted from a ConfigMap after a delay, so a refresh that finds no
+	// changes is tried again for a while
+	configRefreshRetryPeriod = 15 * time.Second
+	configRefreshTimeout     = 2 * time.Minute
+	// The pods are refreshed in parallel, and the reconciler doesn't wait for slow ones for longer than this
+	configRefreshDeadline = 5 * time.Second
+	refreshClient         = &http.Client{}
+)
+
+// ConfigReloadReconciler refreshes the config in the running pods through the actuator when the
+// ConfigMaps and Secrets they use change, and falls back to rolling the pods if that doesn't work
+func ConfigReloadReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("ConfigReload")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) (ctrl.Result, error) {
+			if !refreshesConfig(micro) {
+				micro.Status.ConfigReload = nil
+				return ctrl.Result{}, nil
+			}
+			template := updatePodTemplate(&corev1.PodTemplateSpec{}, resolveBindings(c, micro), micro)
+			configMaps, secrets := envConfigReferences(&template.Spec)
+			envHash, err := hashConfig(ctx, c, micro, configMaps, secrets)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			status := micro.Status.ConfigReload
+			if status == nil {
+				// Start from the config that the pods have already
+				micro.Status.ConfigReload = &api.ConfigReloadStatus{
+					TemplateHash: micro.Status.ConfigHash,
+					ConfigHash:   micro.Status.ConfigHash,
+					EnvHash:      envHash,
+				}
+				return ctrl.Result{}, nil
+			}
+			if status.ConfigHash == micro.Status.ConfigHash {
+				status.PendingSince = nil
+				return ctrl.Result{}, nil
+			}
+			if status.EnvHash != envHash {
+				restartForConfig(c, micro, envHash, "Config in env vars changed")
+				return ctrl.Result{}, nil
+			}
+			pods, err := readyPods(ctx, c, micro)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if micro.Spec.ConfigReload == api.BusRefreshConfigReload && len(pods) > 1 {
+				// The bus takes the event to the other pods
+				pods = pods[:1]
+			}
+			now := metav1.Now()
+			if status.PendingSince == nil {
+				status.PendingSince = &now
+			}
+			if len(pods) > 0 {
+				status.LastRefreshTime = &now
+			}
+			status.Pods = refreshPods(ctx, micro, pods)
+			changed := false
+			for _, pod := range status.Pods {
+				if !pod.Succeeded {
+					restartForConfig(c, micro, envHash, fmt.Sprintf("Refresh failed in pod %q: %s", pod.Name, pod.Message))
+					return ctrl.Result{}, nil
+				}
+				changed = changed || pod.Message != ""
+			}
+			if micro.Spec.ConfigReload == api.RefreshConfigReload && len(pods) > 0 && !changed &&
+				now.Sub(status.PendingSince.Time) < configRefreshTimeout {
+				return ctrl.Result{RequeueAfter: configRefreshRetryPeriod}, nil
+			}
+			if len(pods) > 0 {
+				c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Refreshed", "Refreshed config in %d pods", len(pods))
+			}
+			status.ConfigHash = micro.Status.ConfigHash
+			status.EnvHash = envHash
+			status.PendingSince = nil
+			return ctrl.Result{}, nil
+		},
+
+		Config: c,
+	}
+}
+
+func refreshesConfig(micro *api.Microservice) bool {
+	return micro.Spec.ConfigReload == api.RefreshConfigReload || micro.Spec.ConfigReload == api.BusRefreshConfigReload
+}
+
+// Move the config hash on the pod template on, so the pods roll
+func restartForConfig(c reconcilers.Config, micro *api.Microservice, envHash string, reason string) {
+	c.Recorder.Eventf(micro, corev1.EventTypeWarning, "RefreshFallback", "%s, restarting the pods", reason)
+	status := micro.Status.ConfigReload
+	status.TemplateHash = micro.Status.ConfigHash
+	status.ConfigHash = micro.Status.ConfigHash
+	status.EnvHash = envHash
+	status.PendingSince = nil
+}
+
+// The pods of the Microservice that are ready to take requests
+func readyPods(ctx context.Context, c reconcilers.Config, micro *api.Microservice) ([]corev1.Pod, error) {
+	var list corev1.PodList
+	if err := c.List(ctx, &list, client.InNamespace(micro.Namespace), client.MatchingLabels{"app": micro.Name}); err != nil {
+		return nil, err
+	}
+	pods := []corev1.Pod{}
+	for _, pod := range list.Items {
+		if pod.DeletionTimestamp == nil && pod.Status.PodIP != "" && isPodReady(&pod) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// POST to the refresh endpoint on each of the pods. The message is the list of properties that changed.
+func refreshPods(ctx context.Context, micro *api.Microservice, pods []corev1.Pod) []api.PodRefreshStatus {
+	ctx, cancel := context.WithTimeout(ctx, configRefreshDeadline)
+	defer cancel()
+	path := actuatorBasePath(micro) + "/" + string(micro.Spec.ConfigReload)
+	port := strconv.Itoa(int(managementPort(micro)))
+	results := make([]api.PodRefreshStatus, len(pods))
+	var wg sync.WaitGroup
+	for index, pod := range pods {
+		wg.Add(1)
+		go func(result *api.PodRefreshStatus, pod corev1.Pod) {
+			defer wg.Done()
+			url := fmt.Sprintf("http://%s%s", net.JoinHostPort(pod.Status.PodIP, port), path)
+			result.Name = pod.Name
+			keys, err := refreshPod(ctx, url)
+			if err != nil {
+				result.Message = err.Error()
+				return
+			}
+			result.Succeeded = true
+			result.Message = strings.Join(keys, ",")
+		}(&results[index], pod)
+	}
+	wg.Wait()
+	return results
+}
+
+func refreshPod(ctx context.Context, url string) ([]string, error) {
+	request, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := refreshClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return nil, fmt.Errorf("POST %s returned %s", url, response.Status)
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &keys); err != nil {
+			return nil, fmt.Errorf("POST %s returned unexpected content: %v", url, err)
+		}
+	}
+	return keys, nil
+}
+
+// Make sure the refresh endpoint is exposed over HTTP, keeping any other endpoints that are
+// already exposed
+func addConfigReload(container *corev1.Container, micro *api.Microservice) {
+	if !refreshesConfig(micro) {
+		return
+	}
+	endpoint := string(micro.Spec.ConfigReload)
+	endpoints := []string{"health", "info"}
+	for _, env := range container.Env {
+		if env.Name == "MANAGEMENT_ENDPOINTS_WEB_EXPOSURE_INCLUDE" && env.Value != "" {
+			endpoints = []string{}
+			for _, name := range strings.Split(env.Value, ",") {
+				endpoints = append(endpoints, strings.TrimSpace(name))
+			}
+		}
+	}
+	if containsString(endpoints, "*") || containsString(endpoints, endpoint) {
+		return
+	}
+	endpoints = append(endpoints, endpoint)
+	container.Env = setEnvVar(container.Env, "MANAGEMENT_ENDPOINTS_WEB_EXPOSURE_INCLUDE", strings.Join(endpoints, ","))
+}
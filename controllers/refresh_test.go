@@ -0,0 +1,176 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// A Microservice whose pods are all served by the test server
+func refreshMicroservice(t *testing.T, server *httptest.Server, mode api.ConfigReloadMode) (*api.Microservice, []corev1.Pod) {
+	address, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(address.Port())
+	micro := demoMicroservice(api.MicroserviceSpec{ManagementPort: int32(port), ConfigReload: mode})
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "demo-1"}, Status: corev1.PodStatus{PodIP: address.Hostname()}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "demo-2"}, Status: corev1.PodStatus{PodIP: address.Hostname()}},
+	}
+	return &micro, pods
+}
+
+func TestRefreshPods(t *testing.T) {
+	paths := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Method = %s; want 'POST'", r.Method)
+		}
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`["app.message","logging.level.root"]`))
+	}))
+	defer server.Close()
+	micro, pods := refreshMicroservice(t, server, api.RefreshConfigReload)
+	results := refreshPods(context.Background(), micro, pods)
+	if len(results) != 2 || results[0].Name != "demo-1" || results[1].Name != "demo-2" {
+		t.Errorf("Results = %v; want 'demo-1', 'demo-2'", results)
+	}
+	for _, result := range results {
+		if !result.Succeeded || result.Message != "app.message,logging.level.root" {
+			t.Errorf("Result = %v; want success with 'app.message,logging.level.root'", result)
+		}
+	}
+	if len(paths) != 2 || paths[0] != "/actuator/refresh" {
+		t.Errorf("Paths = %s; want '/actuator/refresh'", paths)
+	}
+}
+
+func TestRefreshPodsBusRefresh(t *testing.T) {
+	paths := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	micro, pods := refreshMicroservice(t, server, api.BusRefreshConfigReload)
+	micro.Spec.Probes = &api.Probes{BasePath: "/manage/"}
+	results := refreshPods(context.Background(), micro, pods[:1])
+	if len(results) != 1 || !results[0].Succeeded || results[0].Message != "" {
+		t.Errorf("Results = %v; want success with no message", results)
+	}
+	if len(paths) != 1 || paths[0] != "/manage/busrefresh" {
+		t.Errorf("Paths = %s; want '/manage/busrefresh'", paths)
+	}
+}
+
+func TestRefreshPodsFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	micro, pods := refreshMicroservice(t, server, api.RefreshConfigReload)
+	results := refreshPods(context.Background(), micro, pods)
+	if len(results) != 2 || results[0].Succeeded || results[0].Message == "" {
+		t.Errorf("Results = %v; want failures with a message", results)
+	}
+}
+
+func TestRefreshPodsDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+	deadline := configRefreshDeadline
+	configRefreshDeadline = 100 * time.Millisecond
+	defer func() { configRefreshDeadline = deadline }()
+	micro, pods := refreshMicroservice(t, server, api.RefreshConfigReload)
+	start := time.Now()
+	results := refreshPods(context.Background(), micro, pods)
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("Elapsed = %s; want the pods refreshed in parallel within the deadline", elapsed)
+	}
+	if len(results) != 2 || results[0].Succeeded || results[1].Succeeded || results[1].Name != pods[1].Name {
+		t.Errorf("Results = %v; want two timeouts", results)
+	}
+}
+
+func TestIsPodReady(t *testing.T) {
+	pod := corev1.Pod{}
+	if isPodReady(&pod) {
+		t.Errorf("isPodReady() = true; want false")
+	}
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	if !isPodReady(&pod) {
+		t.Errorf("isPodReady() = false; want true")
+	}
+}
+
+func TestCreateDeploymentConfigReload(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:        "springguides/demo",
+			ConfigReload: api.RefreshConfigReload,
+		},
+		Status: api.MicroserviceStatus{
+			ConfigHash:   "new",
+			ConfigReload: &api.ConfigReloadStatus{TemplateHash: "old", ConfigHash: "old"},
+		},
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	if deployment.Spec.Template.Annotations["spring.io/config-hash"] != "old" {
+		t.Errorf("Annotations = %s; want 'spring.io/config-hash=old'", deployment.Spec.Template.Annotations)
+	}
+	container := findAppContainer(&deployment.Spec.Template.Spec)
+	if findEnvByName(container.Env, "MANAGEMENT_ENDPOINTS_WEB_EXPOSURE_INCLUDE").Value != "health,info,refresh" {
+		t.Errorf("Env = %v; want 'MANAGEMENT_ENDPOINTS_WEB_EXPOSURE_INCLUDE=health,info,refresh'", container.Env)
+	}
+	micro.Spec.ConfigReload = api.RestartConfigReload
+	deployment = createDeployment([]api.ServiceBinding{}, &micro)
+	if deployment.Spec.Template.Annotations["spring.io/config-hash"] != "new" {
+		t.Errorf("Annotations = %s; want 'spring.io/config-hash=new'", deployment.Spec.Template.Annotations)
+	}
+}
+
+func TestAddConfigReloadKeepsEndpoints(t *testing.T) {
+	micro := api.Microservice{Spec: api.MicroserviceSpec{ConfigReload: api.BusRefreshConfigReload}}
+	container := corev1.Container{Env: []corev1.EnvVar{{Name: "MANAGEMENT_ENDPOINTS_WEB_EXPOSURE_INCLUDE", Value: "health, prometheus"}}}
+	addConfigReload(&container, &micro)
+	if container.Env[0].Value != "health,prometheus,busrefresh" {
+		t.Errorf("Env = %v; want 'health,prometheus,busrefresh'", container.Env)
+	}
+	container.Env[0].Value = "*"
+	addConfigReload(&container, &micro)
+	if container.Env[0].Value != "*" {
+		t.Errorf("Env = %v; want '*'", container.Env)
+	}
+}
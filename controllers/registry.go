@@ -0,0 +1,287 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	defaultRegistry    = "docker.io"
+	dockerHubRegistry  = "registry-1.docker.io"
+	registryHTTPClient = &http.Client{}
+	// All the requests for one image (including the token) have to finish within this time, so a
+	// slow registry doesn't hold up the reconciler
+	registryDeadline   = 10 * time.Second
+	manifestMediaTypes = []string{
+		"application/vnd.oci.image.index.v1+json",
+		"application/vnd.docker.distribution.manifest.list.v2+json",
+		"application/vnd.oci.image.manifest.v1+json",
+		"application/vnd.docker.distribution.manifest.v2+json",
+	}
+	challengeParameter = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// An image reference split into the parts that the registry API needs
+type imageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// Parse an image reference in the same way as docker pull, so springguides/demo is
+// docker.io/springguides/demo:latest
+func parseImageReference(image string) (imageReference, error) {
+	ref := imageReference{}
+	name := image
+	if index := strings.Index(name, "@"); index >= 0 {
+		ref.Digest = name[index+1:]
+		name = name[:index]
+	}
+	if index := strings.LastIndex(name, ":"); index > strings.LastIndex(name, "/") {
+		ref.Tag = name[index+1:]
+		name = name[:index]
+	}
+	ref.Registry = defaultRegistry
+	if index := strings.Index(name, "/"); index >= 0 {
+		first := name[:index]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			ref.Registry = first
+			name = name[index+1:]
+		}
+	}
+	if ref.Registry == defaultRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	if name == "" || name != strings.ToLower(name) {
+		return ref, fmt.Errorf("invalid image reference %q", image)
+	}
+	ref.Repository = name
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+// The host to use for the registry API
+func (ref imageReference) host() string {
+	if ref.Registry == defaultRegistry {
+		return dockerHubRegistry
+	}
+	return ref.Registry
+}
+
+// Credentials for a registry, from an image pull secret
+type registryCredentials struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+func (credentials *registryCredentials) basic() (string, string) {
+	if credentials.Username == "" && credentials.Auth != "" {
+		if decoded, err := base64.StdEncoding.DecodeString(credentials.Auth); err == nil {
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) == 2 {
+				return parts[0], parts[1]
+			}
+		}
+	}
+	return credentials.Username, credentials.Password
+}
+
+// Find the credentials for the registry in the contents of a .dockerconfigjson or .dockercfg
+func findRegistryCredentials(data []byte, registry string) *registryCredentials {
+	config := struct {
+		Auths map[string]registryCredentials `json:"auths"`
+	}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+	auths := config.Auths
+	if auths == nil {
+		// The old .dockercfg format has no "auths" wrapper
+		if err := json.Unmarshal(data, &auths); err != nil {
+			return nil
+		}
+	}
+	for key, credentials := range auths {
+		if registryHost(key) == registryHost(registry) {
+			found := credentials
+			return &found
+		}
+	}
+	return nil
+}
+
+// Normalize the keys of a docker config, which can be URLs, and all mean the same thing for Docker Hub
+func registryHost(key string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	if index := strings.Index(host, "/"); index >= 0 {
+		host = host[:index]
+	}
+	switch host {
+	case "index.docker.io", dockerHubRegistry:
+		return defaultRegistry
+	}
+	return host
+}
+
+// Look up the digest of the manifest (or index) that the tag points at, using the registry API
+func resolveDigest(ctx context.Context, ref imageReference, credentials *registryCredentials, insecure bool) (string, error) {
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, registryDeadline)
+	defer cancel()
+	scheme := "https"
+	if insecure {
+		scheme = "http"
+	}
+	location := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, ref.host(), ref.Repository, ref.Tag)
+	response, err := manifestRequest(ctx, http.MethodHead, location, "")
+	if err != nil {
+		return "", err
+	}
+	authorization := ""
+	if response.StatusCode == http.StatusUnauthorized {
+		authorization, err = authorize(ctx, response.Header.Get("WWW-Authenticate"), ref, credentials)
+		if err != nil {
+			return "", err
+		}
+		response, err = manifestRequest(ctx, http.MethodHead, location, authorization)
+		if err != nil {
+			return "", err
+		}
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HEAD %s returned %s", location, response.Status)
+	}
+	if digest := response.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+	// Not all registries send the digest header, but it is the hash of the manifest
+	response, err = manifestRequest(ctx, http.MethodGet, location, authorization)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s returned %s", location, response.Status)
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(body)), nil
+}
+
+// Send a request for a manifest. The body is closed unless it is a GET.
+func manifestRequest(ctx context.Context, method string, location string, authorization string) (*http.Response, error) {
+	request, err := http.NewRequest(method, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+	response, err := registryHTTPClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if method != http.MethodGet || response.StatusCode != http.StatusOK {
+		response.Body.Close()
+	}
+	return response, nil
+}
+
+// Answer the challenge from the registry with basic credentials, or a bearer token from its token
+// service (anonymous if there are no credentials)
+func authorize(ctx context.Context, challenge string, ref imageReference, credentials *registryCredentials) (string, error) {
+	username, password := "", ""
+	if credentials != nil {
+		username, password = credentials.basic()
+	}
+	scheme := strings.ToLower(strings.SplitN(challenge, " ", 2)[0])
+	if scheme == "basic" {
+		if username == "" {
+			return "", fmt.Errorf("registry %s needs credentials", ref.Registry)
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)), nil
+	}
+	if scheme != "bearer" {
+		return "", fmt.Errorf("registry %s has an unsupported authentication challenge %q", ref.Registry, challenge)
+	}
+	parameters := map[string]string{}
+	for _, match := range challengeParameter.FindAllStringSubmatch(challenge, -1) {
+		parameters[match[1]] = match[2]
+	}
+	realm, err := url.Parse(parameters["realm"])
+	if err != nil || parameters["realm"] == "" {
+		return "", fmt.Errorf("registry %s has no token service in its challenge %q", ref.Registry, challenge)
+	}
+	query := realm.Query()
+	if service := parameters["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := parameters["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", ref.Repository)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+	request, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if username != "" {
+		request.SetBasicAuth(username, password)
+	}
+	response, err := registryHTTPClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s returned %s", realm.String(), response.Status)
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("token service for registry %s returned unexpected content: %v", ref.Registry, err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return "", fmt.Errorf("token service for registry %s returned no token", ref.Registry)
+	}
+	return "Bearer " + token.Token, nil
+}
@@ -0,0 +1,166 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseImageReference(t *testing.T) {
+	for image, want := range map[string]imageReference{
+		"springguides/demo":               {Registry: "docker.io", Repository: "springguides/demo", Tag: "latest"},
+		"ubuntu:20.04":                    {Registry: "docker.io", Repository: "library/ubuntu", Tag: "20.04"},
+		"localhost:5000/demo":             {Registry: "localhost:5000", Repository: "demo", Tag: "latest"},
+		"gcr.io/project/demo:v1":          {Registry: "gcr.io", Repository: "project/demo", Tag: "v1"},
+		"localhost/demo@sha256:abc":       {Registry: "localhost", Repository: "demo", Digest: "sha256:abc"},
+		"springguides/demo:v1@sha256:abc": {Registry: "docker.io", Repository: "springguides/demo", Tag: "v1", Digest: "sha256:abc"},
+	} {
+		ref, err := parseImageReference(image)
+		if err != nil {
+			t.Errorf("parseImageReference(%s) failed: %v", image, err)
+		}
+		if ref != want {
+			t.Errorf("parseImageReference(%s) = %v; want %v", image, ref, want)
+		}
+	}
+	if _, err := parseImageReference("SpringGuides/Demo"); err == nil {
+		t.Errorf("parseImageReference(SpringGuides/Demo) succeeded; want an error")
+	}
+}
+
+func TestFindRegistryCredentials(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	data := []byte(fmt.Sprintf(`{"auths":{"https://index.docker.io/v1/":{"auth":"%s"},"localhost:5000":{"username":"local","password":"pass"}}}`, auth))
+	credentials := findRegistryCredentials(data, "docker.io")
+	if credentials == nil {
+		t.Fatalf("credentials = nil; want some for docker.io")
+	}
+	if username, password := credentials.basic(); username != "user" || password != "secret" {
+		t.Errorf("basic() = %s, %s; want 'user', 'secret'", username, password)
+	}
+	credentials = findRegistryCredentials(data, "localhost:5000")
+	if credentials == nil || credentials.Username != "local" {
+		t.Errorf("credentials = %v; want 'local'", credentials)
+	}
+	if findRegistryCredentials(data, "gcr.io") != nil {
+		t.Errorf("credentials for gcr.io found; want none")
+	}
+	old := []byte(`{"gcr.io":{"username":"old","password":"pass"}}`)
+	if credentials := findRegistryCredentials(old, "gcr.io"); credentials == nil || credentials.Username != "old" {
+		t.Errorf("credentials = %v; want 'old'", credentials)
+	}
+}
+
+// A registry that needs a bearer token from its own token service
+func tokenRegistry(t *testing.T, digest string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.URL.Query().Get("scope") != "repository:demo:pull" || r.URL.Query().Get("service") != "test" {
+				t.Errorf("Query = %s; want scope and service", r.URL.RawQuery)
+			}
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+				t.Errorf("BasicAuth = %s, %s; want 'user', 'secret'", username, password)
+			}
+			w.Write([]byte(`{"token":"abc"}`))
+		case r.URL.Path == "/v2/demo/manifests/latest":
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.Method != http.MethodHead {
+				t.Errorf("Method = %s; want 'HEAD'", r.Method)
+			}
+			if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+				t.Errorf("Accept = %s; want OCI index", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return server
+}
+
+func TestResolveDigestWithToken(t *testing.T) {
+	server := tokenRegistry(t, "sha256:1234")
+	defer server.Close()
+	ref, _ := parseImageReference(strings.TrimPrefix(server.URL, "http://") + "/demo")
+	digest, err := resolveDigest(context.Background(), ref, &registryCredentials{Username: "user", Password: "secret"}, true)
+	if err != nil {
+		t.Fatalf("resolveDigest() failed: %v", err)
+	}
+	if digest != "sha256:1234" {
+		t.Errorf("digest = %s; want 'sha256:1234'", digest)
+	}
+}
+
+func TestResolveDigestWithoutHeader(t *testing.T) {
+	manifest := `{"schemaVersion":2}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/apps/demo/manifests/v1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(manifest))
+	}))
+	defer server.Close()
+	ref, _ := parseImageReference(strings.TrimPrefix(server.URL, "http://") + "/apps/demo:v1")
+	digest, err := resolveDigest(context.Background(), ref, nil, true)
+	if err != nil {
+		t.Fatalf("resolveDigest() failed: %v", err)
+	}
+	if want := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifest))); digest != want {
+		t.Errorf("digest = %s; want '%s'", digest, want)
+	}
+}
+
+func TestResolveDigestNotFound(t *testing.T) {
+	server := tokenRegistry(t, "sha256:1234")
+	defer server.Close()
+	ref, _ := parseImageReference(strings.TrimPrefix(server.URL, "http://") + "/other")
+	if _, err := resolveDigest(context.Background(), ref, nil, true); err == nil {
+		t.Errorf("resolveDigest() succeeded; want an error")
+	}
+}
+
+func TestResolveDigestDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer server.Close()
+	deadline := registryDeadline
+	registryDeadline = 100 * time.Millisecond
+	defer func() { registryDeadline = deadline }()
+	ref, _ := parseImageReference(strings.TrimPrefix(server.URL, "http://") + "/demo")
+	start := time.Now()
+	if _, err := resolveDigest(context.Background(), ref, nil, true); err == nil {
+		t.Errorf("resolveDigest() succeeded; want an error")
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("Elapsed = %s; want the request cancelled at the deadline", elapsed)
+	}
+}
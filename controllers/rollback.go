@@ -0,0 +1,116 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	apps "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var rolledBackAnnotation = "spring.io/rolled-back-from"
+
+// If the rendered pod template is one that has already failed, deploy the last healthy one instead.
+// The healthy template comes from the ReplicaSet that the Deployment kept for that revision (the
+// Deployment controller copies the hash annotation on to its ReplicaSets).
+func applyRollback(micro *api.Microservice, deployment *apps.Deployment, replicaSets []apps.ReplicaSet) *apps.Deployment {
+	if deployment == nil || !micro.Spec.AutoRollback || micro.Status.HealthyRevision == "" {
+		return deployment
+	}
+	hash := deployment.Annotations[specHashAnnotation]
+	if hash != micro.Status.FailedRevision || hash == micro.Status.HealthyRevision {
+		return deployment
+	}
+	for _, replicaSet := range replicaSets {
+		if replicaSet.Annotations[specHashAnnotation] != micro.Status.HealthyRevision {
+			continue
+		}
+		template := replicaSet.Spec.Template.DeepCopy()
+		delete(template.Labels, apps.DefaultDeploymentUniqueLabelKey)
+		deployment.Spec.Template = *template
+		deployment.Annotations[specHashAnnotation] = micro.Status.HealthyRevision
+		deployment.Annotations[rolledBackAnnotation] = hash
+		break
+	}
+	return deployment
+}
+
+// The ReplicaSets of the Deployment, if a rollback might need one of them
+func rollbackReplicaSets(ctx context.Context, c reconcilers.Config, micro *api.Microservice) ([]apps.ReplicaSet, error) {
+	if !micro.Spec.AutoRollback || micro.Status.FailedRevision == "" {
+		return nil, nil
+	}
+	var list apps.ReplicaSetList
+	if err := c.List(ctx, &list, client.InNamespace(micro.Namespace), client.MatchingLabels{"app": micro.Name}); err != nil {
+		return nil, err
+	}
+	replicaSets := []apps.ReplicaSet{}
+	for _, replicaSet := range list.Items {
+		owner := metav1.GetControllerOf(&replicaSet)
+		if owner != nil && owner.Kind == "Deployment" && owner.Name == micro.Name {
+			replicaSets = append(replicaSets, replicaSet)
+		}
+	}
+	return replicaSets, nil
+}
+
+// Keep track of the last healthy revision, and mark the current one as failed if its rollout
+// has stalled. Returns true if a rollback is needed.
+func reflectRollback(micro *api.Microservice, deployment *apps.Deployment) bool {
+	if !micro.Spec.AutoRollback || deployment == nil {
+		micro.Status.HealthyRevision = ""
+		micro.Status.FailedRevision = ""
+		meta.RemoveStatusCondition(&micro.Status.Conditions, api.MicroserviceRolledBack)
+		return false
+	}
+	hash := deployment.Annotations[specHashAnnotation]
+	if failed, ok := deployment.Annotations[rolledBackAnnotation]; ok {
+		meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+			Type:    api.MicroserviceRolledBack,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ProgressDeadlineExceeded",
+			Message: fmt.Sprintf("Revision %q failed to roll out, so revision %q was restored", failed, hash),
+		})
+		return false
+	}
+	meta.RemoveStatusCondition(&micro.Status.Conditions, api.MicroserviceRolledBack)
+	if hash == "" {
+		return false
+	}
+	if isAvailable(deployment) && !progressDeadlineExceeded(micro) {
+		micro.Status.HealthyRevision = hash
+		return false
+	}
+	if progressDeadlineExceeded(micro) && micro.Status.HealthyRevision != "" &&
+		hash != micro.Status.HealthyRevision && hash != micro.Status.FailedRevision {
+		micro.Status.FailedRevision = hash
+		meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+			Type:    api.MicroserviceRolledBack,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ProgressDeadlineExceeded",
+			Message: fmt.Sprintf("Revision %q failed to roll out, so revision %q is being restored", hash, micro.Status.HealthyRevision),
+		})
+		return true
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRollbackRecordsHealthyRevision(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{AutoRollback: true})
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	deployment.Status = apps.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	reflectDeploymentStatus(&micro, deployment)
+	if reflectRollback(&micro, deployment) {
+		t.Errorf("reflectRollback() = true; want false")
+	}
+	if micro.Status.HealthyRevision != deployment.Annotations["spring.io/spec-hash"] {
+		t.Errorf("Status.HealthyRevision = %s; want %s", micro.Status.HealthyRevision, deployment.Annotations["spring.io/spec-hash"])
+	}
+}
+
+func TestRollbackAfterProgressDeadline(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{AutoRollback: true})
+	healthy := createDeployment([]api.ServiceBinding{}, &micro)
+	micro.Status.HealthyRevision = healthy.Annotations["spring.io/spec-hash"]
+	template := healthy.Spec.Template.DeepCopy()
+	template.Labels["pod-template-hash"] = "abc123"
+	replicaSets := []apps.ReplicaSet{{
+		ObjectMeta: metav1.ObjectMeta{Annotations: healthy.Annotations},
+		Spec:       apps.ReplicaSetSpec{Template: *template},
+	}}
+
+	micro.Spec.Image = "springguides/broken"
+	broken := applyRollback(&micro, createDeployment([]api.ServiceBinding{}, &micro), replicaSets)
+	if findAppContainer(&broken.Spec.Template.Spec).Image != "springguides/broken" {
+		t.Errorf("Container.Image = %s; want 'springguides/broken'", findAppContainer(&broken.Spec.Template.Spec).Image)
+	}
+	broken.Status.Conditions = []apps.DeploymentCondition{
+		{Type: apps.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+	}
+	reflectDeploymentStatus(&micro, broken)
+	if !reflectRollback(&micro, broken) {
+		t.Errorf("reflectRollback() = false; want true")
+	}
+	if micro.Status.FailedRevision != broken.Annotations["spring.io/spec-hash"] {
+		t.Errorf("Status.FailedRevision = %s; want %s", micro.Status.FailedRevision, broken.Annotations["spring.io/spec-hash"])
+	}
+	if reflectRollback(&micro, broken) {
+		t.Errorf("reflectRollback() = true; want false (only once)")
+	}
+
+	if applyRollback(&micro, createDeployment([]api.ServiceBinding{}, &micro), nil).Annotations["spring.io/rolled-back-from"] != "" {
+		t.Errorf("Rolled back with no ReplicaSet for the healthy revision")
+	}
+	rolledBack := applyRollback(&micro, createDeployment([]api.ServiceBinding{}, &micro), replicaSets)
+	if findAppContainer(&rolledBack.Spec.Template.Spec).Image != "springguides/demo" {
+		t.Errorf("Container.Image = %s; want 'springguides/demo'", findAppContainer(&rolledBack.Spec.Template.Spec).Image)
+	}
+	if _, ok := rolledBack.Spec.Template.Labels["pod-template-hash"]; ok {
+		t.Errorf("Template.Labels = %s; want no 'pod-template-hash'", rolledBack.Spec.Template.Labels)
+	}
+	if rolledBack.Annotations["spring.io/rolled-back-from"] != micro.Status.FailedRevision {
+		t.Errorf("Annotations = %s; want 'spring.io/rolled-back-from'", rolledBack.Annotations)
+	}
+	reflectRollback(&micro, rolledBack)
+	condition := meta.FindStatusCondition(micro.Status.Conditions, api.MicroserviceRolledBack)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Errorf("Condition = %v; want RolledBack", condition)
+	}
+
+	micro.Spec.Image = "springguides/demo:fixed"
+	fixed := applyRollback(&micro, createDeployment([]api.ServiceBinding{}, &micro), replicaSets)
+	if findAppContainer(&fixed.Spec.Template.Spec).Image != "springguides/demo:fixed" {
+		t.Errorf("Container.Image = %s; want 'springguides/demo:fixed'", findAppContainer(&fixed.Spec.Template.Spec).Image)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	apps "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+// Copy the replica counts and the progress of the latest rollout from the Deployment
+func reflectDeploymentStatus(micro *api.Microservice, deployment *apps.Deployment) {
+	if deployment == nil {
+		micro.Status.Running = false
+		micro.Status.Replicas = 0
+		micro.Status.UpdatedReplicas = 0
+		micro.Status.ReadyReplicas = 0
+		micro.Status.AvailableReplicas = 0
+		meta.RemoveStatusCondition(&micro.Status.Conditions, api.MicroserviceProgressing)
+		return
+	}
+	micro.Status.Running = deployment.Status.AvailableReplicas > 0
+	micro.Status.Replicas = deployment.Status.Replicas
+	micro.Status.UpdatedReplicas = deployment.Status.UpdatedReplicas
+	micro.Status.ReadyReplicas = deployment.Status.ReadyReplicas
+	micro.Status.AvailableReplicas = deployment.Status.AvailableReplicas
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+			Type:    api.MicroserviceProgressing,
+			Status:  metav1.ConditionUnknown,
+			Reason:  "Pending",
+			Message: "Deployment changes have not been observed yet",
+		})
+		return
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == apps.DeploymentProgressing {
+			meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+				Type:    api.MicroserviceProgressing,
+				Status:  metav1.ConditionStatus(condition.Status),
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			})
+			return
+		}
+	}
+	if rolledOut(deployment) {
+		meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+			Type:    api.MicroserviceProgressing,
+			Status:  metav1.ConditionTrue,
+			Reason:  "NewReplicaSetAvailable",
+			Message: "Deployment has successfully progressed",
+		})
+		return
+	}
+	meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+		Type:    api.MicroserviceProgressing,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ReplicaSetUpdated",
+		Message: fmt.Sprintf("%d of %d replicas updated", deployment.Status.UpdatedReplicas, deployment.Status.Replicas),
+	})
+}
+
+func rolledOut(deployment *apps.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	return status.UpdatedReplicas == replicas && status.Replicas == replicas && status.AvailableReplicas == replicas
+}
+
+// True if the Deployment controller gave up waiting for the latest rollout
+func progressDeadlineExceeded(micro *api.Microservice) bool {
+	condition := meta.FindStatusCondition(micro.Status.Conditions, api.MicroserviceProgressing)
+	return condition != nil && condition.Status == metav1.ConditionFalse && condition.Reason == "ProgressDeadlineExceeded"
+}
@@ -0,0 +1,118 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestCreateDeploymentStrategy(t *testing.T) {
+	deadline := int32(120)
+	surge := intstr.FromInt(0)
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "springguides/demo",
+			Strategy: &apps.DeploymentStrategy{
+				Type:          apps.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &apps.RollingUpdateDeployment{MaxSurge: &surge},
+			},
+			MinReadySeconds:         10,
+			ProgressDeadlineSeconds: &deadline,
+		},
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	if deployment.Spec.Strategy.RollingUpdate.MaxSurge.IntValue() != 0 {
+		t.Errorf("Deployment.Spec.Strategy.RollingUpdate.MaxSurge = %s; want '0'", deployment.Spec.Strategy.RollingUpdate.MaxSurge.String())
+	}
+	if deployment.Spec.MinReadySeconds != 10 {
+		t.Errorf("Deployment.Spec.MinReadySeconds = %d; want 10", deployment.Spec.MinReadySeconds)
+	}
+	if *deployment.Spec.ProgressDeadlineSeconds != 120 {
+		t.Errorf("Deployment.Spec.ProgressDeadlineSeconds = %d; want 120", *deployment.Spec.ProgressDeadlineSeconds)
+	}
+}
+
+func TestReflectDeploymentStatusStalled(t *testing.T) {
+	replicas := int32(2)
+	micro := api.Microservice{}
+	deployment := apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       apps.DeploymentSpec{Replicas: &replicas},
+		Status: apps.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           3,
+			UpdatedReplicas:    1,
+			ReadyReplicas:      2,
+			AvailableReplicas:  2,
+			Conditions: []apps.DeploymentCondition{
+				{
+					Type:    apps.DeploymentProgressing,
+					Status:  corev1.ConditionFalse,
+					Reason:  "ProgressDeadlineExceeded",
+					Message: "ReplicaSet \"demo-1234\" has timed out progressing.",
+				},
+			},
+		},
+	}
+	reflectDeploymentStatus(&micro, &deployment)
+	if !micro.Status.Running {
+		t.Errorf("Status.Running = false; want true")
+	}
+	if micro.Status.UpdatedReplicas != 1 {
+		t.Errorf("Status.UpdatedReplicas = %d; want 1", micro.Status.UpdatedReplicas)
+	}
+	if !progressDeadlineExceeded(&micro) {
+		t.Errorf("progressDeadlineExceeded() = false; want true")
+	}
+	reflectDeploymentStatus(&micro, nil)
+	if micro.Status.ReadyReplicas != 0 || len(micro.Status.Conditions) != 0 {
+		t.Errorf("Status = %v; want empty", micro.Status)
+	}
+}
+
+func TestReflectDeploymentStatusRolledOut(t *testing.T) {
+	micro := api.Microservice{}
+	deployment := apps.Deployment{
+		Status: apps.DeploymentStatus{
+			Replicas:          1,
+			UpdatedReplicas:   1,
+			ReadyReplicas:     1,
+			AvailableReplicas: 1,
+		},
+	}
+	reflectDeploymentStatus(&micro, &deployment)
+	condition := meta.FindStatusCondition(micro.Status.Conditions, api.MicroserviceProgressing)
+	if condition.Status != metav1.ConditionTrue || condition.Reason != "NewReplicaSetAvailable" {
+		t.Errorf("Condition = %v; want 'NewReplicaSetAvailable'", condition)
+	}
+	deployment.Generation = 1
+	reflectDeploymentStatus(&micro, &deployment)
+	condition = meta.FindStatusCondition(micro.Status.Conditions, api.MicroserviceProgressing)
+	if condition.Status != metav1.ConditionUnknown {
+		t.Errorf("Condition.Status = %s; want 'Unknown'", condition.Status)
+	}
+}
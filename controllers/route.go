@@ -0,0 +1,255 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var (
+	routeGVK            = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+	routeHashAnnotation = "spring.io/route-hash"
+	routeRequeuePeriod  = 30 * time.Second
+)
+
+// RouteReconciler creates a Gateway API HTTPRoute if needed. The Gateway API is not in the
+// client libraries, and might not be installed in the cluster, so the HTTPRoute is unstructured
+// and not watched. Instead the Microservice is requeued until the route is accepted.
+func RouteReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("Route")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) (ctrl.Result, error) {
+			desired := createRoute(micro)
+			current := &unstructured.Unstructured{}
+			current.SetGroupVersionKind(routeGVK)
+			if err := c.Get(ctx, client.ObjectKey{Namespace: micro.Namespace, Name: micro.Name}, current); err != nil {
+				if meta.IsNoMatchError(err) {
+					if desired == nil {
+						meta.RemoveStatusCondition(&micro.Status.Conditions, api.MicroserviceRouteAccepted)
+					} else {
+						meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+							Type:    api.MicroserviceRouteAccepted,
+							Status:  metav1.ConditionFalse,
+							Reason:  "GatewayAPINotInstalled",
+							Message: "HTTPRoute is not available in this cluster",
+						})
+					}
+					return ctrl.Result{}, nil
+				}
+				if !apierrors.IsNotFound(err) {
+					return ctrl.Result{}, err
+				}
+				current = nil
+			}
+			if current != nil && !metav1.IsControlledBy(current, micro) {
+				if desired != nil {
+					meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+						Type:    api.MicroserviceRouteAccepted,
+						Status:  metav1.ConditionFalse,
+						Reason:  "NotOwned",
+						Message: fmt.Sprintf("HTTPRoute %q already exists", current.GetName()),
+					})
+				}
+				return ctrl.Result{}, nil
+			}
+			if desired == nil {
+				meta.RemoveStatusCondition(&micro.Status.Conditions, api.MicroserviceRouteAccepted)
+				if current != nil {
+					c.Log.Info("deleting unwanted route", "route", current.GetName())
+					if err := c.Delete(ctx, current); err != nil && !apierrors.IsNotFound(err) {
+						c.Recorder.Eventf(micro, corev1.EventTypeWarning, "DeleteFailed",
+							"Failed to delete HTTPRoute %q: %v", current.GetName(), err)
+						return ctrl.Result{}, err
+					}
+					c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Deleted", "Deleted HTTPRoute %q", current.GetName())
+				}
+				return ctrl.Result{}, nil
+			}
+			if err := ctrl.SetControllerReference(micro, desired, c.Scheme()); err != nil {
+				return ctrl.Result{}, err
+			}
+			if current == nil {
+				c.Log.Info("creating route", "route", desired.GetName())
+				if err := c.Create(ctx, desired); err != nil {
+					c.Recorder.Eventf(micro, corev1.EventTypeWarning, "CreationFailed",
+						"Failed to create HTTPRoute %q: %v", desired.GetName(), err)
+					return ctrl.Result{}, err
+				}
+				c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Created", "Created HTTPRoute %q", desired.GetName())
+				current = desired
+			} else if !routeUpToDate(current, desired) {
+				current.SetLabels(desired.GetLabels())
+				current.SetAnnotations(reconcilers.MergeMaps(current.GetAnnotations(), desired.GetAnnotations()))
+				current.Object["spec"] = desired.Object["spec"]
+				c.Log.Info("reconciling route", "route", current.GetName())
+				if err := c.Update(ctx, current); err != nil {
+					c.Recorder.Eventf(micro, corev1.EventTypeWarning, "UpdateFailed",
+						"Failed to update HTTPRoute %q: %v", current.GetName(), err)
+					return ctrl.Result{}, err
+				}
+				c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Updated", "Updated HTTPRoute %q", current.GetName())
+			}
+			if !reflectRouteStatus(micro, current) {
+				return ctrl.Result{RequeueAfter: routeRequeuePeriod}, nil
+			}
+			return ctrl.Result{}, nil
+		},
+
+		Config: c,
+	}
+}
+
+func createRoute(micro *api.Microservice) *unstructured.Unstructured {
+	service := createService(micro)
+	if micro.Spec.Route == nil || service == nil {
+		return nil
+	}
+	options := micro.Spec.Route
+	parent := map[string]interface{}{
+		"name": options.Gateway.Name,
+	}
+	if options.Gateway.Namespace != "" {
+		parent["namespace"] = options.Gateway.Namespace
+	}
+	if options.Gateway.SectionName != "" {
+		parent["sectionName"] = options.Gateway.SectionName
+	}
+	paths := options.Paths
+	if len(paths) == 0 {
+		paths = []string{"/"}
+	}
+	matches := []interface{}{}
+	for _, path := range paths {
+		matches = append(matches, map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":  "PathPrefix",
+				"value": path,
+			},
+		})
+	}
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parent},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": matches,
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": service.Name,
+						"port": int64(service.Spec.Ports[0].Port),
+					},
+				},
+			},
+		},
+	}
+	if len(options.Hostnames) > 0 {
+		hostnames := []interface{}{}
+		for _, hostname := range options.Hostnames {
+			hostnames = append(hostnames, hostname)
+		}
+		spec["hostnames"] = hostnames
+	}
+	route := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	route.SetGroupVersionKind(routeGVK)
+	route.SetName(micro.Name)
+	route.SetNamespace(micro.Namespace)
+	route.SetLabels(map[string]string{"app": micro.Name})
+	// The API server adds defaults to the spec, so compare a hash of the desired spec instead
+	route.SetAnnotations(map[string]string{routeHashAnnotation: computeHash(spec)})
+	return route
+}
+
+func routeUpToDate(current, desired *unstructured.Unstructured) bool {
+	return current.GetAnnotations()[routeHashAnnotation] == desired.GetAnnotations()[routeHashAnnotation] &&
+		equality.Semantic.DeepEqual(current.GetLabels(), desired.GetLabels())
+}
+
+// Reflect the Accepted and ResolvedRefs conditions from the route's parents, returning true if it was accepted
+func reflectRouteStatus(micro *api.Microservice, route *unstructured.Unstructured) bool {
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	if len(parents) == 0 {
+		meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+			Type:    api.MicroserviceRouteAccepted,
+			Status:  metav1.ConditionUnknown,
+			Reason:  "Pending",
+			Message: "Waiting for the Gateway",
+		})
+		return false
+	}
+	for _, parent := range parents {
+		status, _ := parent.(map[string]interface{})
+		conditions, _, _ := unstructured.NestedSlice(status, "conditions")
+		accepted := false
+		for _, item := range conditions {
+			condition, _ := item.(map[string]interface{})
+			conditionType, _, _ := unstructured.NestedString(condition, "type")
+			value, _, _ := unstructured.NestedString(condition, "status")
+			reason, _, _ := unstructured.NestedString(condition, "reason")
+			message, _, _ := unstructured.NestedString(condition, "message")
+			if conditionType != "Accepted" && conditionType != "ResolvedRefs" {
+				continue
+			}
+			if value != string(corev1.ConditionTrue) {
+				meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+					Type:    api.MicroserviceRouteAccepted,
+					Status:  metav1.ConditionFalse,
+					Reason:  reason,
+					Message: message,
+				})
+				return false
+			}
+			if conditionType == "Accepted" {
+				accepted = true
+			}
+		}
+		if !accepted {
+			meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+				Type:    api.MicroserviceRouteAccepted,
+				Status:  metav1.ConditionUnknown,
+				Reason:  "Pending",
+				Message: "Waiting for the Gateway",
+			})
+			return false
+		}
+	}
+	meta.SetStatusCondition(&micro.Status.Conditions, metav1.Condition{
+		Type:    api.MicroserviceRouteAccepted,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Accepted",
+		Message: "",
+	})
+	return true
+}
@@ -0,0 +1,133 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCreateRoute(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "springguides/demo",
+			Route: &api.RouteSpec{
+				Gateway: api.GatewayReference{
+					Name:      "external",
+					Namespace: "infra",
+				},
+				Hostnames: []string{"demo.example.com"},
+			},
+		},
+	}
+	route := createRoute(&micro)
+	if route.GetKind() != "HTTPRoute" {
+		t.Errorf("Kind = %s; want 'HTTPRoute'", route.GetKind())
+	}
+	if route.GetName() != "demo" || route.GetNamespace() != "test" {
+		t.Errorf("Route = %s/%s; want 'test/demo'", route.GetNamespace(), route.GetName())
+	}
+	parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	if len(parents) != 1 {
+		t.Fatalf("len(parentRefs) = %d; want 1", len(parents))
+	}
+	parent := parents[0].(map[string]interface{})
+	if parent["name"] != "external" || parent["namespace"] != "infra" {
+		t.Errorf("parentRefs[0] = %v; want 'infra/external'", parent)
+	}
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if len(hostnames) != 1 || hostnames[0] != "demo.example.com" {
+		t.Errorf("hostnames = %v; want [demo.example.com]", hostnames)
+	}
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	rule := rules[0].(map[string]interface{})
+	backend := rule["backendRefs"].([]interface{})[0].(map[string]interface{})
+	if backend["name"] != "demo" || backend["port"] != int64(80) {
+		t.Errorf("backendRefs[0] = %v; want 'demo:80'", backend)
+	}
+	path, _, _ := unstructured.NestedString(rule["matches"].([]interface{})[0].(map[string]interface{}), "path", "value")
+	if path != "/" {
+		t.Errorf("path = %s; want '/'", path)
+	}
+	// Deep copy must work for unstructured content
+	existing := route.DeepCopy()
+	if !routeUpToDate(existing, route) {
+		t.Errorf("routeUpToDate() = false; want true")
+	}
+	micro.Spec.Route.Paths = []string{"/api"}
+	if routeUpToDate(existing, createRoute(&micro)) {
+		t.Errorf("routeUpToDate() = true; want false when the paths change")
+	}
+}
+
+func routeWithConditions(conditions ...map[string]interface{}) *unstructured.Unstructured {
+	items := []interface{}{}
+	for _, condition := range conditions {
+		items = append(items, condition)
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"parents": []interface{}{
+					map[string]interface{}{
+						"conditions": items,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRouteStatus(t *testing.T) {
+	micro := api.Microservice{}
+	if reflectRouteStatus(&micro, &unstructured.Unstructured{Object: map[string]interface{}{}}) {
+		t.Errorf("reflectRouteStatus() = true; want false")
+	}
+	condition := meta.FindStatusCondition(micro.Status.Conditions, api.MicroserviceRouteAccepted)
+	if condition == nil || condition.Status != metav1.ConditionUnknown {
+		t.Errorf("RouteAccepted = %v; want 'Unknown'", condition)
+	}
+	route := routeWithConditions(
+		map[string]interface{}{"type": "Accepted", "status": "True", "reason": "Accepted"},
+		map[string]interface{}{"type": "ResolvedRefs", "status": "False", "reason": "BackendNotFound", "message": "no service"},
+	)
+	if reflectRouteStatus(&micro, route) {
+		t.Errorf("reflectRouteStatus() = true; want false")
+	}
+	condition = meta.FindStatusCondition(micro.Status.Conditions, api.MicroserviceRouteAccepted)
+	if condition.Status != metav1.ConditionFalse || condition.Reason != "BackendNotFound" {
+		t.Errorf("RouteAccepted = %s %s; want 'False BackendNotFound'", condition.Status, condition.Reason)
+	}
+	route = routeWithConditions(
+		map[string]interface{}{"type": "Accepted", "status": "True", "reason": "Accepted"},
+		map[string]interface{}{"type": "ResolvedRefs", "status": "True", "reason": "ResolvedRefs"},
+	)
+	if !reflectRouteStatus(&micro, route) {
+		t.Errorf("reflectRouteStatus() = false; want true")
+	}
+	condition = meta.FindStatusCondition(micro.Status.Conditions, api.MicroserviceRouteAccepted)
+	if condition.Status != metav1.ConditionTrue {
+		t.Errorf("RouteAccepted = %s; want 'True'", condition.Status)
+	}
+}
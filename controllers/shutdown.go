@@ -0,0 +1,65 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var defaultPreStopSeconds = int32(10)
+var defaultShutdownTimeoutSeconds = int32(30)
+
+// Extra time for the JVM to exit after the last shutdown phase
+var shutdownMarginSeconds = int64(5)
+
+// Wire up graceful shutdown: sleep in a preStop hook while the endpoints are removed, then give
+// Spring Boot enough time to finish in-flight requests before the kubelet kills the container
+func addGracefulShutdown(template *corev1.PodTemplateSpec, container *corev1.Container, micro *api.Microservice) {
+	options := micro.Spec.GracefulShutdown
+	if options == nil {
+		return
+	}
+	preStop := defaultPreStopSeconds
+	if options.PreStopSeconds != nil {
+		preStop = *options.PreStopSeconds
+	}
+	timeout := defaultShutdownTimeoutSeconds
+	if options.TimeoutSeconds != nil {
+		timeout = *options.TimeoutSeconds
+	}
+	if preStop > 0 {
+		if container.Lifecycle == nil {
+			container.Lifecycle = &corev1.Lifecycle{}
+		}
+		if container.Lifecycle.PreStop == nil {
+			container.Lifecycle.PreStop = &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{
+					Command: []string{"sh", "-c", fmt.Sprintf("sleep %d", preStop)},
+				},
+			}
+		}
+	}
+	container.Env = setEnvVar(container.Env, "SERVER_SHUTDOWN", "graceful")
+	container.Env = setEnvVar(container.Env, "SPRING_LIFECYCLE_TIMEOUT_PER_SHUTDOWN_PHASE", fmt.Sprintf("%ds", timeout))
+	grace := int64(preStop) + int64(timeout) + shutdownMarginSeconds
+	if template.Spec.TerminationGracePeriodSeconds == nil || *template.Spec.TerminationGracePeriodSeconds < grace {
+		template.Spec.TerminationGracePeriodSeconds = &grace
+	}
+}
@@ -0,0 +1,125 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateDeploymentGracefulShutdown(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:            "springguides/demo",
+			GracefulShutdown: &api.GracefulShutdown{},
+		},
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	spec := deployment.Spec.Template.Spec
+	container := findAppContainer(&spec)
+	if container.Lifecycle == nil || container.Lifecycle.PreStop == nil {
+		t.Fatalf("Container.Lifecycle = %v; want preStop", container.Lifecycle)
+	}
+	if container.Lifecycle.PreStop.Exec.Command[2] != "sleep 10" {
+		t.Errorf("PreStop = %s; want 'sleep 10'", container.Lifecycle.PreStop.Exec.Command)
+	}
+	if *spec.TerminationGracePeriodSeconds != 45 {
+		t.Errorf("TerminationGracePeriodSeconds = %d; want 45", *spec.TerminationGracePeriodSeconds)
+	}
+	if findEnvByName(container.Env, "SERVER_SHUTDOWN").Value != "graceful" {
+		t.Errorf("SERVER_SHUTDOWN = %s; want 'graceful'", findEnvByName(container.Env, "SERVER_SHUTDOWN").Value)
+	}
+	if findEnvByName(container.Env, "SPRING_LIFECYCLE_TIMEOUT_PER_SHUTDOWN_PHASE").Value != "30s" {
+		t.Errorf("SPRING_LIFECYCLE_TIMEOUT_PER_SHUTDOWN_PHASE = %s; want '30s'", findEnvByName(container.Env, "SPRING_LIFECYCLE_TIMEOUT_PER_SHUTDOWN_PHASE").Value)
+	}
+}
+
+func TestCreateDeploymentGracefulShutdownCustom(t *testing.T) {
+	zero := int32(0)
+	timeout := int32(20)
+	grace := int64(120)
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:            "springguides/demo",
+			GracefulShutdown: &api.GracefulShutdown{PreStopSeconds: &zero, TimeoutSeconds: &timeout},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					TerminationGracePeriodSeconds: &grace,
+					Containers:                    []corev1.Container{{Name: "app"}},
+				},
+			},
+		},
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	spec := deployment.Spec.Template.Spec
+	container := findAppContainer(&spec)
+	if container.Lifecycle != nil {
+		t.Errorf("Container.Lifecycle = %v; want nil", container.Lifecycle)
+	}
+	if *spec.TerminationGracePeriodSeconds != 120 {
+		t.Errorf("TerminationGracePeriodSeconds = %d; want 120", *spec.TerminationGracePeriodSeconds)
+	}
+	if findEnvByName(container.Env, "SPRING_LIFECYCLE_TIMEOUT_PER_SHUTDOWN_PHASE").Value != "20s" {
+		t.Errorf("SPRING_LIFECYCLE_TIMEOUT_PER_SHUTDOWN_PHASE = %s; want '20s'", findEnvByName(container.Env, "SPRING_LIFECYCLE_TIMEOUT_PER_SHUTDOWN_PHASE").Value)
+	}
+}
+
+func TestCreateDeploymentGracefulShutdownWithSidecar(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:            "springguides/demo",
+			GracefulShutdown: &api.GracefulShutdown{},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "proxy", Image: "envoyproxy/envoy"}, {Name: "app"}},
+				},
+			},
+		},
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) != 2 {
+		t.Fatalf("len(Containers) = %d; want 2", len(containers))
+	}
+	sidecar, container := containers[0], containers[1]
+	if container.Image != "springguides/demo" {
+		t.Errorf("Container.Image = %s; want 'springguides/demo'", container.Image)
+	}
+	if len(container.Ports) == 0 || container.Ports[0].Name != "http" {
+		t.Errorf("Container.Ports = %v; want 'http'", container.Ports)
+	}
+	if container.Lifecycle == nil || container.Lifecycle.PreStop == nil {
+		t.Errorf("Container.Lifecycle = %v; want preStop", container.Lifecycle)
+	}
+	if sidecar.Image != "envoyproxy/envoy" || len(sidecar.Ports) != 0 || sidecar.Lifecycle != nil {
+		t.Errorf("Containers[0] = %v; want the sidecar unchanged", sidecar)
+	}
+}
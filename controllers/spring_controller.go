@@ -18,6 +18,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/vmware-labs/reconciler-runtime/reconcilers"
@@ -25,6 +26,7 @@ import (
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -41,14 +43,25 @@ import (
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=endpoints;pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 
 var (
-	ownerKey = ".metadata.controller"
-	apiGVStr = api.GroupVersion.String()
+	ownerKey          = ".metadata.controller"
+	apiGVStr          = api.GroupVersion.String()
+	defaultServerPort = int32(8080)
 )
 
 // MicroserviceReconciler reconciles a Microservice object
@@ -57,10 +70,31 @@ func MicroserviceReconciler(c reconcilers.Config) *reconcilers.ParentReconciler
 
 	return &reconcilers.ParentReconciler{
 		Type: &api.Microservice{},
-		SubReconcilers: []reconcilers.SubReconciler{
+		Reconciler: reconcilers.Sequence{
 			DeploymentBindingReconciler(c),
+			MemoryCalculatorReconciler(c),
+			PropertiesReconciler(c),
+			PropertiesFromReconciler(c),
+			PodReferencesReconciler(c),
+			ImageReconciler(c),
+			ConfigHashReconciler(c),
+			ConfigReloadReconciler(c),
+			ServiceAccountReconciler(c),
+			RoleReconciler(c),
+			RoleBindingReconciler(c),
+			CanaryReconciler(c),
 			DeploymentReconciler(c),
+			StatefulSetReconciler(c),
+			BlueGreenReconciler(c),
+			HorizontalPodAutoscalerReconciler(c),
+			PodDisruptionBudgetReconciler(c),
+			JobReconciler(c),
+			CronJobReconciler(c),
+			ServiceClusterIPReconciler(c),
 			ServiceReconciler(c),
+			IngressReconciler(c),
+			RouteReconciler(c),
+			DebugServiceReconciler(c),
 		},
 
 		Config: c,
@@ -136,9 +170,9 @@ func DeploymentBindingReconciler(c reconcilers.Config) reconcilers.SubReconciler
 
 		Config: c,
 
-		Setup: func(mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
-			config.SetupWithManager(mgr, bldr)
-			secret.SetupWithManager(mgr, bldr)
+		Setup: func(ctx context.Context, mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
+			config.SetupWithManager(ctx, mgr, bldr)
+			secret.SetupWithManager(ctx, mgr, bldr)
 			return nil
 		},
 	}
@@ -160,11 +194,10 @@ func ConfigMapReconciler(c reconcilers.Config, r *ContextReconciler) reconcilers
 	c.Log = c.Log.WithName("ConfigMap")
 	return &reconcilers.ChildReconciler{
 		Config:        c,
-		ParentType:    &api.Microservice{},
 		ChildType:     &corev1.ConfigMap{},
 		ChildListType: &corev1.ConfigMapList{},
 
-		DesiredChild: func(micro *api.Microservice) (*corev1.ConfigMap, error) {
+		DesiredChild: func(ctx context.Context, micro *api.Microservice) (*corev1.ConfigMap, error) {
 			return &r.config, nil
 		},
 
@@ -181,8 +214,6 @@ func ConfigMapReconciler(c reconcilers.Config, r *ContextReconciler) reconcilers
 			return equality.Semantic.DeepEqual(a1.Labels, a2.Labels) && equality.Semantic.DeepEqual(a1.Data, a2.Data)
 		},
 
-		IndexField: ".metadata.serviceBindingConfigMap",
-
 		Sanitize: func(child *corev1.ConfigMap) interface{} {
 			return child.Data
 		},
@@ -194,11 +225,10 @@ func SecretReconciler(c reconcilers.Config, r *ContextReconciler) reconcilers.Su
 	c.Log = c.Log.WithName("ConfigMap")
 	return &reconcilers.ChildReconciler{
 		Config:        c,
-		ParentType:    &api.Microservice{},
 		ChildType:     &corev1.Secret{},
 		ChildListType: &corev1.SecretList{},
 
-		DesiredChild: func(micro *api.Microservice) (*corev1.Secret, error) {
+		DesiredChild: func(ctx context.Context, micro *api.Microservice) (*corev1.Secret, error) {
 			return &r.secret, nil
 		},
 
@@ -215,8 +245,6 @@ func SecretReconciler(c reconcilers.Config, r *ContextReconciler) reconcilers.Su
 			return equality.Semantic.DeepEqual(a1.Labels, a2.Labels) && equality.Semantic.DeepEqual(a1.Data, a2.Data)
 		},
 
-		IndexField: ".metadata.serviceBindingSecretp",
-
 		Sanitize: func(child *corev1.Secret) interface{} {
 			return child.Data
 		},
@@ -229,46 +257,70 @@ func DeploymentReconciler(c reconcilers.Config) reconcilers.SubReconciler {
 
 	return &reconcilers.ChildReconciler{
 		Config:        c,
-		ParentType:    &api.Microservice{},
 		ChildType:     &apps.Deployment{},
 		ChildListType: &apps.DeploymentList{},
 
-		DesiredChild: func(micro *api.Microservice) (*apps.Deployment, error) {
-			bindingsToApply := findBindings(c, micro)
-			trackBindings(c, bindingsToApply)
-			updatedBindings := updateBindings(c, micro, bindingsToApply)
-			return createDeployment(updatedBindings, micro), nil
+		DesiredChild: func(ctx context.Context, micro *api.Microservice) (*apps.Deployment, error) {
+			if isBlueGreen(micro) && !blueGreenSwitched(micro) {
+				// Keep the old Deployment serving until the first colour is available
+				return nil, reconcilers.OnlyReconcileChildStatus
+			}
+			if runsToCompletion(micro) || isStatefulSet(micro) || isBlueGreen(micro) {
+				return nil, nil
+			}
+			replicaSets, err := rollbackReplicaSets(ctx, c, micro)
+			if err != nil {
+				return nil, err
+			}
+			return applyRollback(micro, createDeployment(resolveBindings(c, micro), micro), replicaSets), nil
 		},
 
 		ReflectChildStatusOnParent: func(micro *api.Microservice, child *apps.Deployment, err error) {
 			if err != nil {
 				return
 			}
-			if child == nil {
-				micro.Status.Running = false
-			} else {
-				micro.Status.Running = child.Status.AvailableReplicas > 0
+			stalled := progressDeadlineExceeded(micro)
+			reflectDeploymentStatus(micro, child)
+			if !stalled && progressDeadlineExceeded(micro) {
+				c.Recorder.Eventf(micro, corev1.EventTypeWarning, "ProgressDeadlineExceeded",
+					"Rollout of Deployment %q has stalled", child.Name)
+			}
+			if reflectRollback(micro, child) {
+				c.Recorder.Eventf(micro, corev1.EventTypeWarning, "RollingBack",
+					"Rolling Deployment %q back to revision %q", child.Name, micro.Status.HealthyRevision)
+			}
+		},
+
+		HarmonizeImmutableFields: func(current, desired *apps.Deployment) {
+			if desired.Spec.Replicas == nil {
+				// Something else (e.g. an HPA) owns the replica count
+				desired.Spec.Replicas = current.Spec.Replicas
 			}
 		},
 
 		MergeBeforeUpdate: func(current, desired *apps.Deployment) {
 			current.Labels = desired.Labels
+			// The deployment controller adds annotations of its own
+			current.Annotations = reconcilers.MergeMaps(current.Annotations, desired.Annotations)
+			if _, ok := desired.Annotations[rolledBackAnnotation]; !ok {
+				delete(current.Annotations, rolledBackAnnotation)
+			}
 			current.Spec = desired.Spec
 		},
 
 		SemanticEquals: func(a1, a2 *apps.Deployment) bool {
 			return equality.Semantic.DeepEqual(a1.Spec, a2.Spec) &&
-				equality.Semantic.DeepEqual(a1.Labels, a2.Labels)
+				equality.Semantic.DeepEqual(a1.Labels, a2.Labels) &&
+				a1.Annotations[specHashAnnotation] == a2.Annotations[specHashAnnotation] &&
+				a1.Annotations[rolledBackAnnotation] == a2.Annotations[rolledBackAnnotation]
 		},
 
-		IndexField: ".metadata.deploymentController",
-
 		Sanitize: func(child *apps.Deployment) interface{} {
 			return child.Spec
 		},
 
-		Setup: func(mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
-			bldr.Watches(&source.Kind{Type: &api.ServiceBinding{}}, reconcilers.EnqueueTracked(&api.ServiceBinding{}, c.Tracker, c.Scheme))
+		Setup: func(ctx context.Context, mgr reconcilers.Manager, bldr *reconcilers.Builder) error {
+			bldr.Watches(&source.Kind{Type: &api.ServiceBinding{}}, reconcilers.EnqueueTracked(&api.ServiceBinding{}, c.Tracker, c.Scheme()))
 			return nil
 		},
 	}
@@ -280,11 +332,10 @@ func ServiceReconciler(c reconcilers.Config) reconcilers.SubReconciler {
 
 	return &reconcilers.ChildReconciler{
 		Config:        c,
-		ParentType:    &api.Microservice{},
 		ChildType:     &corev1.Service{},
 		ChildListType: &corev1.ServiceList{},
 
-		DesiredChild: func(micro *api.Microservice) (*corev1.Service, error) {
+		DesiredChild: func(ctx context.Context, micro *api.Microservice) (*corev1.Service, error) {
 			return createService(micro), nil
 		},
 
@@ -294,53 +345,162 @@ func ServiceReconciler(c reconcilers.Config) reconcilers.SubReconciler {
 			}
 			if child != nil {
 				micro.Status.ServiceName = child.ObjectMeta.Name
+			} else {
+				micro.Status.ServiceName = ""
 			}
 		},
 
 		HarmonizeImmutableFields: func(current, desired *corev1.Service) {
-			desired.Spec.ClusterIP = current.Spec.ClusterIP
+			harmonizeService(current, desired)
 		},
 
 		MergeBeforeUpdate: func(current, desired *corev1.Service) {
 			current.Labels = desired.Labels
+			// Cloud load balancer controllers add annotations of their own
+			current.Annotations = reconcilers.MergeMaps(current.Annotations, desired.Annotations)
 			current.Spec = desired.Spec
 		},
 
 		SemanticEquals: func(a1, a2 *corev1.Service) bool {
 			return equality.Semantic.DeepEqual(a1.Spec, a2.Spec) &&
-				equality.Semantic.DeepEqual(a1.Labels, a2.Labels)
+				equality.Semantic.DeepEqual(a1.Labels, a2.Labels) &&
+				hasAnnotations(a2.Annotations, a1.Annotations)
 		},
 
-		IndexField: ".metadata.serviceController",
-
 		Sanitize: func(child *corev1.Service) interface{} {
 			return child.Spec
 		},
 	}
 }
 
+// ServiceClusterIPReconciler deletes the Service if it has to switch to or from being headless
+func ServiceClusterIPReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("ServiceClusterIP")
+
+	return &reconcilers.SyncReconciler{
+
+		Sync: func(ctx context.Context, micro *api.Microservice) error {
+			desired := createService(micro)
+			if desired == nil {
+				return nil
+			}
+			var current corev1.Service
+			if err := c.Get(ctx, client.ObjectKey{Namespace: desired.Namespace, Name: desired.Name}, &current); err != nil {
+				if apierrors.IsNotFound(err) {
+					return nil
+				}
+				return err
+			}
+			if !metav1.IsControlledBy(&current, micro) || isHeadless(&current) == isHeadless(desired) {
+				return nil
+			}
+			// The cluster IP is immutable, so the Service has to be re-created
+			c.Log.Info("deleting service to change its cluster IP", "service", current.Name)
+			if err := c.Delete(ctx, &current); err != nil && !apierrors.IsNotFound(err) {
+				c.Recorder.Eventf(micro, corev1.EventTypeWarning, "DeleteFailed",
+					"Failed to delete Service %q: %v", current.Name, err)
+				return err
+			}
+			c.Recorder.Eventf(micro, corev1.EventTypeNormal, "Deleted", "Deleted Service %q", current.Name)
+			return nil
+		},
+
+		Config: c,
+	}
+}
+
+// Copy the fields that are allocated by the API server from the current Service
+func harmonizeService(current, desired *corev1.Service) {
+	if isHeadless(current) == isHeadless(desired) {
+		desired.Spec.ClusterIP = current.Spec.ClusterIP
+	}
+	if desired.Spec.Type != corev1.ServiceTypeNodePort && desired.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return
+	}
+	for index, port := range desired.Spec.Ports {
+		if port.NodePort != 0 {
+			continue
+		}
+		for _, existing := range current.Spec.Ports {
+			if existing.Name == port.Name && existing.Port == port.Port {
+				desired.Spec.Ports[index].NodePort = existing.NodePort
+			}
+		}
+	}
+	if desired.Spec.HealthCheckNodePort == 0 {
+		desired.Spec.HealthCheckNodePort = current.Spec.HealthCheckNodePort
+	}
+}
+
+func isHeadless(service *corev1.Service) bool {
+	return service.Spec.ClusterIP == corev1.ClusterIPNone
+}
+
 func createService(micro *api.Microservice) *corev1.Service {
+	if runsToCompletion(micro) {
+		// A job has no endpoints to expose
+		return nil
+	}
+	options := micro.Spec.Service
+	if options == nil {
+		options = &api.ServiceSpec{}
+	}
+	if options.Disabled && !isStatefulSet(micro) {
+		return nil
+	}
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels:    map[string]string{"app": micro.Name},
-			Name:      micro.Name,
-			Namespace: micro.Namespace,
+			Labels:      reconcilers.MergeMaps(options.Labels, map[string]string{"app": micro.Name}),
+			Annotations: options.Annotations,
+			Name:        micro.Name,
+			Namespace:   micro.Namespace,
 		},
 		Spec: corev1.ServiceSpec{
 			Ports: []corev1.ServicePort{
 				{
 					Protocol:   "TCP",
 					Port:       80,
-					TargetPort: intstr.FromInt(8080),
+					TargetPort: intstr.FromInt(int(serverPort(micro))),
 					Name:       "http",
 				},
 			},
-			Selector: map[string]string{"app": micro.Name},
+			Selector: serviceSelector(micro),
 		},
 	}
+	if port := managementPort(micro); port != serverPort(micro) {
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+			Protocol:   "TCP",
+			Port:       port,
+			TargetPort: intstr.FromInt(int(port)),
+			Name:       "management",
+		})
+	}
+	for _, port := range options.Ports {
+		if findServicePort(service.Spec.Ports, port.Name) == nil {
+			service.Spec.Ports = append(service.Spec.Ports, port)
+		}
+	}
+	service.Spec.Type = options.Type
+	if options.Headless {
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+	if isStatefulSet(micro) {
+		// The governing Service of a StatefulSet has to be headless
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+	}
 	return service
 }
 
+func findServicePort(ports []corev1.ServicePort, name string) *corev1.ServicePort {
+	for index, port := range ports {
+		if port.Name == name {
+			return &ports[index]
+		}
+	}
+	return nil
+}
+
 func createDeployment(bindings []api.ServiceBinding, micro *api.Microservice) *apps.Deployment {
 	deployment := &apps.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -355,7 +515,14 @@ func createDeployment(bindings []api.ServiceBinding, micro *api.Microservice) *a
 			Template: corev1.PodTemplateSpec{},
 		},
 	}
+	deployment.Spec.Replicas = workloadReplicas(micro)
+	deployment.Spec.MinReadySeconds = micro.Spec.MinReadySeconds
+	deployment.Spec.ProgressDeadlineSeconds = micro.Spec.ProgressDeadlineSeconds
+	if micro.Spec.Strategy != nil {
+		deployment.Spec.Strategy = *micro.Spec.Strategy.DeepCopy()
+	}
 	deployment.Spec.Template = *updatePodTemplate(&deployment.Spec.Template, bindings, micro)
+	deployment.Annotations = map[string]string{specHashAnnotation: computeHash(deployment.Spec.Template)}
 	return deployment
 }
 
@@ -377,6 +544,16 @@ func updatePodTemplate(template *corev1.PodTemplateSpec, bindings []api.ServiceB
 	container.Env = defaults.Env
 	mergeEnvVars(container, bindings)
 	addProfiles(container, micro.Spec)
+	addPorts(container, micro)
+	addMemoryOptions(container, micro)
+	addProperties(template, container, micro)
+	addPropertiesFrom(container, micro)
+	addGracefulShutdown(template, container, micro)
+	addProbes(container, micro)
+	addDebug(container, micro)
+	addConfigReload(container, micro)
+	addPodSettings(template, container, micro)
+	addConfigHash(template, micro)
 	if template.ObjectMeta.Labels == nil {
 		template.ObjectMeta.Labels = map[string]string{}
 	}
@@ -384,10 +561,41 @@ func updatePodTemplate(template *corev1.PodTemplateSpec, bindings []api.ServiceB
 	return template
 }
 
+// The pods that get traffic from the Service
+func serviceSelector(micro *api.Microservice) map[string]string {
+	selector := map[string]string{"app": micro.Name}
+	if blueGreenSwitched(micro) {
+		selector[colorLabel] = activeColor(micro)
+	}
+	return selector
+}
+
+// The replicas for the Deployment or StatefulSet, or nil if something else (e.g. an HPA) owns the
+// replica count
+func workloadReplicas(micro *api.Microservice) *int32 {
+	if micro.Spec.Debug {
+		one := int32(1)
+		return &one
+	}
+	if micro.Spec.Autoscaling == nil {
+		return micro.Spec.Replicas
+	}
+	return nil
+}
+
+// A Microservice that is a Job or a CronJob runs to completion instead of being a long-lived Deployment
+func runsToCompletion(micro *api.Microservice) bool {
+	return micro.Spec.Job || micro.Spec.Schedule != ""
+}
+
+func isStatefulSet(micro *api.Microservice) bool {
+	return micro.Spec.Workload == api.StatefulSetWorkload && !runsToCompletion(micro)
+}
+
 // Set up the app container, setting the image, adding args etc.
 func setUpAppContainer(container *corev1.Container, micro api.Microservice) {
 	container.Name = "app"
-	container.Image = micro.Spec.Image
+	container.Image = appImage(micro)
 	if len(micro.Spec.Args) > 0 {
 		container.Args = micro.Spec.Args
 	}
@@ -399,9 +607,9 @@ func findAppContainer(pod *corev1.PodSpec) *corev1.Container {
 	if len(pod.Containers) == 1 {
 		container = &pod.Containers[0]
 	} else {
-		for _, candidate := range pod.Containers {
-			if candidate.Name == "app" {
-				container = &candidate
+		for i := range pod.Containers {
+			if pod.Containers[i].Name == "app" {
+				container = &pod.Containers[i]
 				break
 			}
 		}
@@ -442,11 +650,12 @@ func mergeEnvVars(container *corev1.Container, bindings []api.ServiceBinding) {
 		// Append bindings onto CSV values supplied in container
 		multis[name] = unique(append(strings.Split(value.Value, ","), multis[name]...))
 	}
-	for key, value := range singles {
-		env = setEnvVar(env, key, value)
+	// Iterate in a fixed order so that the rendered template is stable
+	for _, key := range sortedKeys(singles) {
+		env = setEnvVar(env, key, singles[key])
 	}
-	for key, value := range multis {
-		env = setEnvVar(env, key, strings.Join(value, ","))
+	for _, key := range sortedKeys(multis) {
+		env = setEnvVar(env, key, strings.Join(multis[key], ","))
 	}
 	container.Env = env
 }
@@ -457,6 +666,65 @@ func addProfiles(container *corev1.Container, spec api.MicroserviceSpec) {
 	}
 }
 
+// The port that the app listens on
+func serverPort(micro *api.Microservice) int32 {
+	if micro.Spec.Port > 0 {
+		return micro.Spec.Port
+	}
+	return defaultServerPort
+}
+
+// The port that the actuator endpoints listen on
+func managementPort(micro *api.Microservice) int32 {
+	if micro.Spec.ManagementPort > 0 {
+		return micro.Spec.ManagementPort
+	}
+	return serverPort(micro)
+}
+
+// Declare the container ports and tell Spring Boot about them if they are not the defaults
+func addPorts(container *corev1.Container, micro *api.Microservice) {
+	server := serverPort(micro)
+	management := managementPort(micro)
+	container.Ports = setContainerPort(container.Ports, "http", server)
+	if micro.Spec.Port > 0 {
+		container.Env = setEnvVar(container.Env, "SERVER_PORT", fmt.Sprint(server))
+	}
+	if management != server {
+		container.Ports = setContainerPort(container.Ports, "management", management)
+		container.Env = setEnvVar(container.Env, "MANAGEMENT_SERVER_PORT", fmt.Sprint(management))
+	}
+	if management != defaultServerPort {
+		for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe, container.StartupProbe} {
+			adjustActuatorProbe(probe, management)
+		}
+	}
+}
+
+func setContainerPort(ports []corev1.ContainerPort, name string, port int32) []corev1.ContainerPort {
+	for _, existing := range ports {
+		if existing.Name == name || existing.ContainerPort == port {
+			// Declared already in the template
+			return ports
+		}
+	}
+	return append(ports, corev1.ContainerPort{
+		Name:          name,
+		ContainerPort: port,
+		Protocol:      corev1.ProtocolTCP,
+	})
+}
+
+// Point actuator probes that assume the default port (e.g. from the "actuators" binding) at the management port
+func adjustActuatorProbe(probe *corev1.Probe, port int32) {
+	if probe == nil || probe.HTTPGet == nil || !strings.HasPrefix(probe.HTTPGet.Path, "/actuator") {
+		return
+	}
+	if probe.HTTPGet.Port.IntValue() == int(defaultServerPort) || probe.HTTPGet.Port.String() == "http" {
+		probe.HTTPGet.Port = intstr.FromInt(int(port))
+	}
+}
+
 func setEnvVar(values []corev1.EnvVar, name string, value string) []corev1.EnvVar {
 	var env corev1.EnvVar
 	var index int
@@ -475,6 +743,25 @@ func setEnvVar(values []corev1.EnvVar, name string, value string) []corev1.EnvVa
 	return values
 }
 
+// Check that the current annotations include all the desired ones (others may have been added by other controllers)
+func hasAnnotations(current, desired map[string]string) bool {
+	for key, value := range desired {
+		if existing, ok := current[key]; !ok || existing != value {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func unique(values []string) []string {
 	sifted := map[string]bool{}
 	result := []string{}
@@ -511,6 +798,13 @@ func trackBindings(c reconcilers.Config, bindingsToApply []api.ServiceBinding) {
 	}
 }
 
+// Find, track and localize the bindings that apply to the Microservice
+func resolveBindings(c reconcilers.Config, micro *api.Microservice) []api.ServiceBinding {
+	bindingsToApply := findBindings(c, micro)
+	trackBindings(c, bindingsToApply)
+	return updateBindings(c, micro, bindingsToApply)
+}
+
 func findBindings(c reconcilers.Config, micro *api.Microservice) []api.ServiceBinding {
 	var bindingsToApply []api.ServiceBinding
 	if len(micro.Spec.Bindings) > 0 {
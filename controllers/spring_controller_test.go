@@ -61,6 +61,135 @@ func TestCreateService(t *testing.T) {
 	}
 }
 
+func TestCreateServiceJob(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "busybox",
+			Job:   true,
+		},
+	}
+	service := createService(&micro)
+	if service != nil {
+		t.Errorf("Service = %v; want nil", service)
+	}
+}
+
+func TestCreateServiceOptions(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "springguides/demo",
+			Service: &api.ServiceSpec{
+				Type:        corev1.ServiceTypeNodePort,
+				Labels:      map[string]string{"tier": "web", "app": "other"},
+				Annotations: map[string]string{"foo": "bar"},
+				Ports: []corev1.ServicePort{
+					{
+						Name:       "grpc",
+						Port:       9090,
+						TargetPort: intstr.FromInt(9090),
+					},
+				},
+			},
+		},
+	}
+	service := createService(&micro)
+	if service.Spec.Type != corev1.ServiceTypeNodePort {
+		t.Errorf("Service.Spec.Type = %s; want 'NodePort'", service.Spec.Type)
+	}
+	if service.Labels["tier"] != "web" {
+		t.Errorf("Service.Labels['tier'] = %s; want 'web'", service.Labels["tier"])
+	}
+	if service.Labels["app"] != "demo" {
+		t.Errorf("Service.Labels['app'] = %s; want 'demo'", service.Labels["app"])
+	}
+	if service.Annotations["foo"] != "bar" {
+		t.Errorf("Service.Annotations['foo'] = %s; want 'bar'", service.Annotations["foo"])
+	}
+	if len(service.Spec.Ports) != 2 {
+		t.Fatalf("len(Service.Spec.Ports) = %d; want 2", len(service.Spec.Ports))
+	}
+	if service.Spec.Ports[1].Name != "grpc" {
+		t.Errorf("Service.Spec.Ports[1].Name = %s; want 'grpc'", service.Spec.Ports[1].Name)
+	}
+	if service.Spec.ClusterIP != "" {
+		t.Errorf("Service.Spec.ClusterIP = %s; want ''", service.Spec.ClusterIP)
+	}
+	micro.Spec.Service.Headless = true
+	service = createService(&micro)
+	if service.Spec.ClusterIP != corev1.ClusterIPNone {
+		t.Errorf("Service.Spec.ClusterIP = %s; want 'None'", service.Spec.ClusterIP)
+	}
+	micro.Spec.Service.Disabled = true
+	if service := createService(&micro); service != nil {
+		t.Errorf("Service = %v; want nil", service)
+	}
+}
+
+func TestHarmonizeService(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "demo",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "springguides/demo",
+			Service: &api.ServiceSpec{
+				Type: corev1.ServiceTypeLoadBalancer,
+			},
+		},
+	}
+	current := createService(&micro)
+	current.Spec.ClusterIP = "10.0.0.1"
+	current.Spec.Ports[0].NodePort = 30080
+	current.Spec.HealthCheckNodePort = 30999
+	desired := createService(&micro)
+	harmonizeService(current, desired)
+	if desired.Spec.ClusterIP != "10.0.0.1" {
+		t.Errorf("Service.Spec.ClusterIP = %s; want '10.0.0.1'", desired.Spec.ClusterIP)
+	}
+	if desired.Spec.Ports[0].NodePort != 30080 {
+		t.Errorf("Service.Spec.Ports[0].NodePort = %d; want 30080", desired.Spec.Ports[0].NodePort)
+	}
+	if desired.Spec.HealthCheckNodePort != 30999 {
+		t.Errorf("Service.Spec.HealthCheckNodePort = %d; want 30999", desired.Spec.HealthCheckNodePort)
+	}
+	micro.Spec.Service.Type = corev1.ServiceTypeClusterIP
+	desired = createService(&micro)
+	harmonizeService(current, desired)
+	if desired.Spec.Ports[0].NodePort != 0 {
+		t.Errorf("Service.Spec.Ports[0].NodePort = %d; want 0", desired.Spec.Ports[0].NodePort)
+	}
+	micro.Spec.Service.Headless = true
+	desired = createService(&micro)
+	harmonizeService(current, desired)
+	if desired.Spec.ClusterIP != corev1.ClusterIPNone {
+		t.Errorf("Service.Spec.ClusterIP = %s; want 'None'", desired.Spec.ClusterIP)
+	}
+}
+
+func TestHasAnnotations(t *testing.T) {
+	current := map[string]string{"foo": "bar", "cloud.example.com/id": "123"}
+	if !hasAnnotations(current, map[string]string{"foo": "bar"}) {
+		t.Errorf("hasAnnotations() = false; want true")
+	}
+	if !hasAnnotations(current, nil) {
+		t.Errorf("hasAnnotations(nil) = false; want true")
+	}
+	if hasAnnotations(current, map[string]string{"foo": "baz"}) {
+		t.Errorf("hasAnnotations(foo=baz) = true; want false")
+	}
+	if hasAnnotations(nil, map[string]string{"foo": "bar"}) {
+		t.Errorf("hasAnnotations() with no current annotations = true; want false")
+	}
+}
+
 func TestCreateDeploymentVanilla(t *testing.T) {
 	micro := api.Microservice{
 		ObjectMeta: metav1.ObjectMeta{
@@ -97,6 +226,20 @@ func TestCreateDeploymentVanilla(t *testing.T) {
 
 }
 
+// A Microservice called "demo" in the "test" namespace, with the demo image unless the spec has one
+func demoMicroservice(spec api.MicroserviceSpec) api.Microservice {
+	if spec.Image == "" {
+		spec.Image = "springguides/demo"
+	}
+	return api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: spec,
+	}
+}
+
 func defaultBinding(name string, micro api.Microservice) api.ServiceBinding {
 	appContainer := corev1.Container{
 		Name: "app",
@@ -114,7 +257,7 @@ func defaultBinding(name string, micro api.Microservice) api.ServiceBinding {
 	binding.Namespace = micro.Namespace
 	if name == "actuators" {
 		appContainer.LivenessProbe = &corev1.Probe{
-			Handler: corev1.Handler{
+			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
 					Path: "/actuator/info",
 					Port: intstr.FromInt(8080),
@@ -124,7 +267,7 @@ func defaultBinding(name string, micro api.Microservice) api.ServiceBinding {
 			PeriodSeconds:       11,
 		}
 		appContainer.ReadinessProbe = &corev1.Probe{
-			Handler: corev1.Handler{
+			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
 					Path: "/actuator/health",
 					Port: intstr.FromInt(8080),
@@ -292,6 +435,76 @@ func TestCreateDeploymentActuators(t *testing.T) {
 
 }
 
+func TestCreateDeploymentPorts(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Bindings:       []string{"actuators"},
+			Image:          "springguides/demo",
+			Port:           9000,
+			ManagementPort: 9001,
+		},
+	}
+	deployment := createDeployment([]api.ServiceBinding{defaultBinding("actuators", micro)}, &micro)
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if len(container.Ports) != 2 {
+		t.Fatalf("len(Container.Ports) = %d; want 2", len(container.Ports))
+	}
+	if container.Ports[0].Name != "http" || container.Ports[0].ContainerPort != 9000 {
+		t.Errorf("Container.Ports[0] = %s:%d; want 'http:9000'", container.Ports[0].Name, container.Ports[0].ContainerPort)
+	}
+	if container.Ports[1].Name != "management" || container.Ports[1].ContainerPort != 9001 {
+		t.Errorf("Container.Ports[1] = %s:%d; want 'management:9001'", container.Ports[1].Name, container.Ports[1].ContainerPort)
+	}
+	if findEnvByName(container.Env, "SERVER_PORT").Value != "9000" {
+		t.Errorf("SERVER_PORT = %s; want '9000'", findEnvByName(container.Env, "SERVER_PORT").Value)
+	}
+	if findEnvByName(container.Env, "MANAGEMENT_SERVER_PORT").Value != "9001" {
+		t.Errorf("MANAGEMENT_SERVER_PORT = %s; want '9001'", findEnvByName(container.Env, "MANAGEMENT_SERVER_PORT").Value)
+	}
+	if container.LivenessProbe.HTTPGet.Port.IntValue() != 9001 {
+		t.Errorf("LivenessProbe port = %s; want 9001", container.LivenessProbe.HTTPGet.Port.String())
+	}
+	if container.ReadinessProbe.HTTPGet.Port.IntValue() != 9001 {
+		t.Errorf("ReadinessProbe port = %s; want 9001", container.ReadinessProbe.HTTPGet.Port.String())
+	}
+	service := createService(&micro)
+	if len(service.Spec.Ports) != 2 {
+		t.Fatalf("len(Service.Spec.Ports) = %d; want 2", len(service.Spec.Ports))
+	}
+	if service.Spec.Ports[0].Port != 80 || service.Spec.Ports[0].TargetPort.IntValue() != 9000 {
+		t.Errorf("Service.Spec.Ports[0] = %d:%s; want 80:9000", service.Spec.Ports[0].Port, service.Spec.Ports[0].TargetPort.String())
+	}
+	if service.Spec.Ports[1].Port != 9001 || service.Spec.Ports[1].TargetPort.IntValue() != 9001 {
+		t.Errorf("Service.Spec.Ports[1] = %d:%s; want 9001:9001", service.Spec.Ports[1].Port, service.Spec.Ports[1].TargetPort.String())
+	}
+}
+
+func TestCreateDeploymentDefaultPorts(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "demo",
+		},
+		Spec: api.MicroserviceSpec{
+			Image: "springguides/demo",
+		},
+	}
+	deployment := createDeployment([]api.ServiceBinding{defaultBinding("actuators", micro)}, &micro)
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if len(container.Ports) != 1 || container.Ports[0].ContainerPort != 8080 {
+		t.Errorf("Container.Ports = %v; want [http:8080]", container.Ports)
+	}
+	if findEnvByName(container.Env, "SERVER_PORT").Name != "__empty" {
+		t.Errorf("SERVER_PORT = %s; want unset", findEnvByName(container.Env, "SERVER_PORT").Value)
+	}
+	if findEnvByName(container.Env, "MANAGEMENT_SERVER_PORT").Name != "__empty" {
+		t.Errorf("MANAGEMENT_SERVER_PORT = %s; want unset", findEnvByName(container.Env, "MANAGEMENT_SERVER_PORT").Value)
+	}
+}
+
 func TestCreateDeploymentExistingAnonymousContainer(t *testing.T) {
 	micro := api.Microservice{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,206 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	corev1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+var readOnly = []string{"get", "list", "watch"}
+
+// ServiceAccountReconciler creates a new ServiceAccount for Spring Cloud Kubernetes if needed
+func ServiceAccountReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("ServiceAccount")
+
+	return &reconcilers.ChildReconciler{
+		Config:        c,
+		ChildType:     &corev1.ServiceAccount{},
+		ChildListType: &corev1.ServiceAccountList{},
+
+		DesiredChild: func(ctx context.Context, micro *api.Microservice) (*corev1.ServiceAccount, error) {
+			return createServiceAccount(micro), nil
+		},
+
+		ReflectChildStatusOnParent: func(micro *api.Microservice, child *corev1.ServiceAccount, err error) {
+			return
+		},
+
+		MergeBeforeUpdate: func(current, desired *corev1.ServiceAccount) {
+			current.Labels = desired.Labels
+		},
+
+		SemanticEquals: func(a1, a2 *corev1.ServiceAccount) bool {
+			return equality.Semantic.DeepEqual(a1.Labels, a2.Labels)
+		},
+
+		Sanitize: func(child *corev1.ServiceAccount) interface{} {
+			return child.Name
+		},
+	}
+}
+
+// RoleReconciler creates a new Role for Spring Cloud Kubernetes if needed
+func RoleReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("Role")
+
+	return &reconcilers.ChildReconciler{
+		Config:        c,
+		ChildType:     &rbac.Role{},
+		ChildListType: &rbac.RoleList{},
+
+		DesiredChild: func(ctx context.Context, micro *api.Microservice) (*rbac.Role, error) {
+			return createRole(micro), nil
+		},
+
+		ReflectChildStatusOnParent: func(micro *api.Microservice, child *rbac.Role, err error) {
+			return
+		},
+
+		MergeBeforeUpdate: func(current, desired *rbac.Role) {
+			current.Labels = desired.Labels
+			current.Rules = desired.Rules
+		},
+
+		SemanticEquals: func(a1, a2 *rbac.Role) bool {
+			return equality.Semantic.DeepEqual(a1.Rules, a2.Rules) &&
+				equality.Semantic.DeepEqual(a1.Labels, a2.Labels)
+		},
+
+		Sanitize: func(child *rbac.Role) interface{} {
+			return child.Rules
+		},
+	}
+}
+
+// RoleBindingReconciler creates a new RoleBinding for Spring Cloud Kubernetes if needed
+func RoleBindingReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("RoleBinding")
+
+	return &reconcilers.ChildReconciler{
+		Config:        c,
+		ChildType:     &rbac.RoleBinding{},
+		ChildListType: &rbac.RoleBindingList{},
+
+		DesiredChild: func(ctx context.Context, micro *api.Microservice) (*rbac.RoleBinding, error) {
+			return createRoleBinding(micro), nil
+		},
+
+		ReflectChildStatusOnParent: func(micro *api.Microservice, child *rbac.RoleBinding, err error) {
+			return
+		},
+
+		HarmonizeImmutableFields: func(current, desired *rbac.RoleBinding) {
+			desired.RoleRef = current.RoleRef
+		},
+
+		MergeBeforeUpdate: func(current, desired *rbac.RoleBinding) {
+			current.Labels = desired.Labels
+			current.Subjects = desired.Subjects
+		},
+
+		SemanticEquals: func(a1, a2 *rbac.RoleBinding) bool {
+			return equality.Semantic.DeepEqual(a1.Subjects, a2.Subjects) &&
+				equality.Semantic.DeepEqual(a1.Labels, a2.Labels)
+		},
+
+		Sanitize: func(child *rbac.RoleBinding) interface{} {
+			return child.Subjects
+		},
+	}
+}
+
+// A ServiceAccount for the pods, unless the spec names one explicitly
+func createServiceAccount(micro *api.Microservice) *corev1.ServiceAccount {
+	if micro.Spec.SpringCloudKubernetes == nil || micro.Spec.ServiceAccountName != "" {
+		return nil
+	}
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    map[string]string{"app": micro.Name},
+			Name:      micro.Name,
+			Namespace: micro.Namespace,
+		},
+	}
+}
+
+func createRole(micro *api.Microservice) *rbac.Role {
+	features := micro.Spec.SpringCloudKubernetes
+	if features == nil {
+		return nil
+	}
+	role := &rbac.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    map[string]string{"app": micro.Name},
+			Name:      micro.Name,
+			Namespace: micro.Namespace,
+		},
+		Rules: []rbac.PolicyRule{},
+	}
+	if features.Config {
+		role.Rules = append(role.Rules, rbac.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: readOnly})
+	}
+	if features.Secrets {
+		role.Rules = append(role.Rules, rbac.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: readOnly})
+	}
+	if features.Discovery {
+		role.Rules = append(role.Rules, rbac.PolicyRule{APIGroups: []string{""}, Resources: []string{"services", "endpoints", "pods"}, Verbs: readOnly})
+	}
+	if features.LeaderElection {
+		role.Rules = append(role.Rules, rbac.PolicyRule{
+			APIGroups: []string{"coordination.k8s.io"},
+			Resources: []string{"leases"},
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+		})
+		if !features.Discovery {
+			// The leader is identified by its pod
+			role.Rules = append(role.Rules, rbac.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: readOnly})
+		}
+	}
+	return role
+}
+
+func createRoleBinding(micro *api.Microservice) *rbac.RoleBinding {
+	if micro.Spec.SpringCloudKubernetes == nil {
+		return nil
+	}
+	return &rbac.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    map[string]string{"app": micro.Name},
+			Name:      micro.Name,
+			Namespace: micro.Namespace,
+		},
+		RoleRef: rbac.RoleRef{
+			APIGroup: rbac.GroupName,
+			Kind:     "Role",
+			Name:     micro.Name,
+		},
+		Subjects: []rbac.Subject{
+			{
+				Kind:      rbac.ServiceAccountKind,
+				Name:      serviceAccountName(micro),
+				Namespace: micro.Namespace,
+			},
+		},
+	}
+}
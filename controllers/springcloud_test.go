@@ -0,0 +1,76 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+func TestCreateRole(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{SpringCloudKubernetes: &api.SpringCloudKubernetes{Config: true, LeaderElection: true}})
+	role := createRole(&micro)
+	if len(role.Rules) != 3 {
+		t.Fatalf("Role.Rules = %v; want 3 rules", role.Rules)
+	}
+	if role.Rules[0].Resources[0] != "configmaps" {
+		t.Errorf("Role.Rules[0] = %v; want 'configmaps'", role.Rules[0])
+	}
+	if role.Rules[1].APIGroups[0] != "coordination.k8s.io" || role.Rules[1].Resources[0] != "leases" {
+		t.Errorf("Role.Rules[1] = %v; want 'leases'", role.Rules[1])
+	}
+	for _, rule := range role.Rules {
+		for _, resource := range rule.Resources {
+			if resource == "secrets" {
+				t.Errorf("Role.Rules = %v; want no 'secrets'", role.Rules)
+			}
+		}
+	}
+	micro.Spec.SpringCloudKubernetes = &api.SpringCloudKubernetes{Discovery: true}
+	role = createRole(&micro)
+	if len(role.Rules) != 1 || len(role.Rules[0].Resources) != 3 {
+		t.Errorf("Role.Rules = %v; want services, endpoints and pods", role.Rules)
+	}
+}
+
+func TestCreateServiceAccountAndBinding(t *testing.T) {
+	micro := demoMicroservice(api.MicroserviceSpec{SpringCloudKubernetes: &api.SpringCloudKubernetes{Config: true}})
+	if createServiceAccount(&micro).Name != "demo" {
+		t.Errorf("ServiceAccount.Name = %s; want 'demo'", createServiceAccount(&micro).Name)
+	}
+	binding := createRoleBinding(&micro)
+	if binding.RoleRef.Name != "demo" || binding.Subjects[0].Name != "demo" {
+		t.Errorf("RoleBinding = %v; want role and service account 'demo'", binding)
+	}
+	deployment := createDeployment([]api.ServiceBinding{}, &micro)
+	if deployment.Spec.Template.Spec.ServiceAccountName != "demo" {
+		t.Errorf("ServiceAccountName = %s; want 'demo'", deployment.Spec.Template.Spec.ServiceAccountName)
+	}
+
+	micro.Spec.ServiceAccountName = "custom"
+	if createServiceAccount(&micro) != nil {
+		t.Errorf("ServiceAccount = not nil; want nil")
+	}
+	if createRoleBinding(&micro).Subjects[0].Name != "custom" {
+		t.Errorf("RoleBinding.Subjects = %v; want 'custom'", createRoleBinding(&micro).Subjects)
+	}
+
+	micro.Spec.SpringCloudKubernetes = nil
+	if createRole(&micro) != nil || createRoleBinding(&micro) != nil {
+		t.Errorf("Role or RoleBinding = not nil; want nil")
+	}
+}
@@ -0,0 +1,113 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/vmware-labs/reconciler-runtime/reconcilers"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+)
+
+// StatefulSetReconciler creates a new StatefulSet if needed
+func StatefulSetReconciler(c reconcilers.Config) reconcilers.SubReconciler {
+	c.Log = c.Log.WithName("StatefulSet")
+
+	return &reconcilers.ChildReconciler{
+		Config:        c,
+		ChildType:     &apps.StatefulSet{},
+		ChildListType: &apps.StatefulSetList{},
+
+		DesiredChild: func(ctx context.Context, micro *api.Microservice) (*apps.StatefulSet, error) {
+			if !isStatefulSet(micro) {
+				return nil, nil
+			}
+			return createStatefulSet(resolveBindings(c, micro), micro), nil
+		},
+
+		ReflectChildStatusOnParent: func(micro *api.Microservice, child *apps.StatefulSet, err error) {
+			if err != nil || !isStatefulSet(micro) {
+				return
+			}
+			if child == nil {
+				micro.Status.Running = false
+				return
+			}
+			micro.Status.Running = child.Status.ReadyReplicas > 0
+			micro.Status.Replicas = child.Status.Replicas
+			micro.Status.UpdatedReplicas = child.Status.UpdatedReplicas
+			micro.Status.ReadyReplicas = child.Status.ReadyReplicas
+			micro.Status.AvailableReplicas = child.Status.ReadyReplicas
+		},
+
+		HarmonizeImmutableFields: func(current, desired *apps.StatefulSet) {
+			if desired.Spec.Replicas == nil {
+				// Something else (e.g. an HPA) owns the replica count
+				desired.Spec.Replicas = current.Spec.Replicas
+			}
+			// Only the replicas, template and update strategy of a StatefulSet can be changed
+			desired.Spec.Selector = current.Spec.Selector
+			desired.Spec.ServiceName = current.Spec.ServiceName
+			desired.Spec.VolumeClaimTemplates = current.Spec.VolumeClaimTemplates
+			desired.Spec.PodManagementPolicy = current.Spec.PodManagementPolicy
+		},
+
+		MergeBeforeUpdate: func(current, desired *apps.StatefulSet) {
+			current.Labels = desired.Labels
+			current.Spec = desired.Spec
+		},
+
+		SemanticEquals: func(a1, a2 *apps.StatefulSet) bool {
+			return equality.Semantic.DeepEqual(a1.Spec, a2.Spec) &&
+				equality.Semantic.DeepEqual(a1.Labels, a2.Labels)
+		},
+
+		Sanitize: func(child *apps.StatefulSet) interface{} {
+			return child.Spec
+		},
+	}
+}
+
+func createStatefulSet(bindings []api.ServiceBinding, micro *api.Microservice) *apps.StatefulSet {
+	statefulSet := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    map[string]string{"app": micro.Name},
+			Name:      micro.Name,
+			Namespace: micro.Namespace,
+		},
+		Spec: apps.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": micro.Name},
+			},
+			// The governing Service is the one from createService()
+			ServiceName: micro.Name,
+			Template:    corev1.PodTemplateSpec{},
+		},
+	}
+	statefulSet.Spec.Replicas = workloadReplicas(micro)
+	for _, claim := range micro.Spec.VolumeClaimTemplates {
+		claim = *claim.DeepCopy()
+		claim.Status = corev1.PersistentVolumeClaimStatus{}
+		statefulSet.Spec.VolumeClaimTemplates = append(statefulSet.Spec.VolumeClaimTemplates, claim)
+	}
+	statefulSet.Spec.Template = *updatePodTemplate(&statefulSet.Spec.Template, bindings, micro)
+	return statefulSet
+}
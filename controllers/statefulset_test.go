@@ -0,0 +1,104 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	api "github.com/dsyer/spring-boot-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateStatefulSet(t *testing.T) {
+	replicas := int32(3)
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:    "springguides/demo",
+			Workload: api.StatefulSetWorkload,
+			Replicas: &replicas,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "state"},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+						},
+					},
+				},
+			},
+		},
+	}
+	statefulSet := createStatefulSet([]api.ServiceBinding{}, &micro)
+	if statefulSet.Spec.ServiceName != "demo" {
+		t.Errorf("StatefulSet.Spec.ServiceName = %s; want 'demo'", statefulSet.Spec.ServiceName)
+	}
+	if *statefulSet.Spec.Replicas != 3 {
+		t.Errorf("StatefulSet.Spec.Replicas = %d; want 3", *statefulSet.Spec.Replicas)
+	}
+	if len(statefulSet.Spec.VolumeClaimTemplates) != 1 || statefulSet.Spec.VolumeClaimTemplates[0].Name != "state" {
+		t.Errorf("StatefulSet.Spec.VolumeClaimTemplates = %v; want 'state'", statefulSet.Spec.VolumeClaimTemplates)
+	}
+	container := findAppContainer(&statefulSet.Spec.Template.Spec)
+	if container.Image != "springguides/demo" {
+		t.Errorf("Container.Image = %s; want 'springguides/demo'", container.Image)
+	}
+	if statefulSet.Spec.Template.Labels["app"] != "demo" {
+		t.Errorf("Template.Labels = %s; want 'app=demo'", statefulSet.Spec.Template.Labels)
+	}
+}
+
+func TestCreateServiceStatefulSet(t *testing.T) {
+	micro := api.Microservice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+		},
+		Spec: api.MicroserviceSpec{
+			Image:    "springguides/demo",
+			Workload: api.StatefulSetWorkload,
+			Service:  &api.ServiceSpec{Disabled: true, Type: corev1.ServiceTypeNodePort},
+		},
+	}
+	service := createService(&micro)
+	if service == nil {
+		t.Fatalf("Service = nil; want not nil")
+	}
+	if !isHeadless(service) {
+		t.Errorf("Service.Spec.ClusterIP = %s; want 'None'", service.Spec.ClusterIP)
+	}
+	if service.Spec.Type != corev1.ServiceTypeClusterIP {
+		t.Errorf("Service.Spec.Type = %s; want 'ClusterIP'", service.Spec.Type)
+	}
+}
+
+func TestStatefulSetJob(t *testing.T) {
+	micro := api.Microservice{
+		Spec: api.MicroserviceSpec{
+			Workload: api.StatefulSetWorkload,
+			Job:      true,
+		},
+	}
+	if isStatefulSet(&micro) {
+		t.Errorf("isStatefulSet() = true; want false")
+	}
+}
@@ -64,7 +64,7 @@ func main() {
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flag.Parse()
 
-	ctrl.SetLogger(zap.Logger(true))
+	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
@@ -80,16 +80,16 @@ func main() {
 		os.Exit(1)
 	}
 
+	ctx := ctrl.SetupSignalHandler()
 	if err = controllers.MicroserviceReconciler(
 		reconcilers.Config{
 			Client:    mgr.GetClient(),
 			APIReader: mgr.GetAPIReader(),
 			Recorder:  mgr.GetEventRecorderFor("Microservice"),
 			Log:       ctrl.Log.WithName("controllers").WithName("Microservice"),
-			Scheme:    mgr.GetScheme(),
 			Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("Microservice").WithName("tracker")),
 		},
-	).SetupWithManager(mgr); err != nil {
+	).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Microservice")
 		os.Exit(1)
 	}
@@ -100,10 +100,9 @@ func main() {
 			APIReader: mgr.GetAPIReader(),
 			Recorder:  mgr.GetEventRecorderFor("ServiceBinding"),
 			Log:       ctrl.Log.WithName("controllers").WithName("ServiceBinding"),
-			Scheme:    mgr.GetScheme(),
 			Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("ServiceBinding").WithName("tracker")),
 		},
-	).SetupWithManager(mgr); err != nil {
+	).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ServiceBinding")
 		os.Exit(1)
 	}
@@ -118,7 +117,7 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}